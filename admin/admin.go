@@ -0,0 +1,234 @@
+// Package admin provides http.Handler that exposes the factory registry and
+// tracked instances as JSON, intended to be mounted on admin or debug port.
+//
+// Typical usage:
+//
+//	factory.TrackInstances(true)
+//	mux.Handle("/factory/", http.StripPrefix("/factory", &admin.Handler{Validate: true}))
+//
+// Endpoints, relative to the mount point:
+//
+//	GET  /factories        list registered factories
+//	GET  /factories/{name} describe factory and its option schema
+//	GET  /instances        list tracked instances with their health
+//	POST /validate         validate submitted manifest or config (if Validate is set)
+//	POST /reload           call Reload (if Reload is set) and report factory.EventReloaded
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ipsusila/factory"
+)
+
+// DefaultHealthTimeout is used when Handler.HealthTimeout is zero
+const DefaultHealthTimeout = 5 * time.Second
+
+// maxBodySize limits size of submitted config
+const maxBodySize = 4 << 20
+
+// Handler serves registry introspection endpoints.
+// Zero value serves read only endpoints.
+type Handler struct {
+	// Reload is called by POST /reload, the endpoint is disabled if nil
+	Reload func(ctx context.Context) error
+
+	// Validate enables POST /validate
+	Validate bool
+
+	// HealthTimeout limits time spent on health checks of all instances
+	HealthTimeout time.Duration
+}
+
+// InstanceInfo is JSON representation of tracked instance
+type InstanceInfo struct {
+	ID      string    `json:"id"`
+	Key     string    `json:"key"`
+	Factory string    `json:"factory"`
+	Created time.Time `json:"created"`
+	Healthy bool      `json:"healthy"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// ValidationError reports invalid instance of submitted config
+type ValidationError struct {
+	Instance string `json:"instance,omitempty"`
+	Error    string `json:"error"`
+}
+
+// ValidationResult is response of POST /validate
+type ValidationResult struct {
+	Valid  bool              `json:"valid"`
+	Errors []ValidationError `json:"errors,omitempty"`
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case path == "factories":
+		if allow(w, r, http.MethodGet) {
+			h.listFactories(w)
+		}
+	case strings.HasPrefix(path, "factories/"):
+		if allow(w, r, http.MethodGet) {
+			h.describeFactory(w, strings.TrimPrefix(path, "factories/"))
+		}
+	case path == "instances":
+		if allow(w, r, http.MethodGet) {
+			h.listInstances(w, r)
+		}
+	case path == "validate" && h.Validate:
+		if allow(w, r, http.MethodPost) {
+			h.validate(w, r)
+		}
+	case path == "reload" && h.Reload != nil:
+		if allow(w, r, http.MethodPost) {
+			h.reload(w, r)
+		}
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s not found", r.URL.Path))
+	}
+}
+
+// listFactories write every registered factory
+func (h *Handler) listFactories(w http.ResponseWriter) {
+	list := factory.Factories()
+	infos := make([]factory.Description, len(list))
+	for i, f := range list {
+		infos[i] = f.Describe()
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// describeFactory write factory with given name
+func (h *Handler) describeFactory(w http.ResponseWriter, name string) {
+	f := factory.Get(name)
+	if f == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("factory %s is not registered", name))
+		return
+	}
+	writeJSON(w, http.StatusOK, f.Describe())
+}
+
+// listInstances write tracked instances after checking their health
+func (h *Handler) listInstances(w http.ResponseWriter, r *http.Request) {
+	timeout := h.HealthTimeout
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	list := factory.Instances()
+	infos := make([]InstanceInfo, len(list))
+	for i, inst := range list {
+		infos[i] = InstanceInfo{
+			ID:      inst.Object.ID(),
+			Key:     inst.Key,
+			Factory: inst.Factory,
+			Created: inst.Created,
+			Healthy: true,
+		}
+		if err := inst.Health(ctx); err != nil {
+			infos[i].Healthy = false
+			infos[i].Error = err.Error()
+		}
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// validate check submitted manifest or single config without creating objects.
+// Active profiles are given as comma separated profile query parameter.
+func (h *Handler) validate(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	configs, err := resolve(data, r.URL.Query().Get("profile"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res := ValidationResult{Valid: true}
+	for _, c := range configs {
+		if err := factory.Validate(c); err != nil {
+			res.Valid = false
+			res.Errors = append(res.Errors, ValidationError{Instance: c.Key(), Error: err.Error()})
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// resolve decode manifest or single config (object without instances) and resolve its instances
+func resolve(data []byte, profile string) ([]factory.Config, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if _, ok := doc["instances"]; !ok {
+		var c factory.Config
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, err
+		}
+		return []factory.Config{c}, nil
+	}
+
+	var m factory.Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if len(m.Include) > 0 {
+		return nil, errors.New("include is not supported in submitted manifest")
+	}
+	var profiles []string
+	if profile != "" {
+		profiles = strings.Split(profile, ",")
+	}
+	return m.Resolve(profiles...)
+}
+
+// reload call Reload hook and report EventReloaded
+func (h *Handler) reload(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	err := h.Reload(r.Context())
+	factory.NotifyReload(time.Since(start), err)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// allow write method not allowed error if request method is not the given method
+func allow(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method || method == http.MethodGet && r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+// writeError write error as JSON object
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeJSON write indented JSON with given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
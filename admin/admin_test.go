@@ -0,0 +1,166 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/ipsusila/factory/admin"
+	"github.com/stretchr/testify/assert"
+)
+
+type conn struct {
+	id   string
+	down bool
+}
+
+func (c *conn) ID() string {
+	return c.id
+}
+
+func (c *conn) Health(ctx context.Context) error {
+	if c.down {
+		return errors.New("connection refused")
+	}
+	return ctx.Err()
+}
+
+func init() {
+	info := factory.Info{
+		Name:    "conn",
+		Version: "v0.1.0",
+		Options: []factory.OptionSpec{
+			{Name: "addr", Type: "hostport", Required: true},
+			{Name: "token", Type: "string", Default: "changeme", Secret: true},
+			{Name: "down", Type: "bool"},
+		},
+	}
+	factory.Register("conn", info, func(o factory.Options) (factory.Object, error) {
+		return &conn{id: o.String("addr"), down: o.Bool("down")}, nil
+	})
+}
+
+func serve(h http.Handler, method, target, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	var res map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &res)
+	return rec, res
+}
+
+func TestFactories(t *testing.T) {
+	h := &admin.Handler{}
+	rec, _ := serve(h, http.MethodGet, "/factories", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	var infos []factory.Description
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &infos))
+	assert.Len(t, infos, 1)
+	assert.Equal(t, "conn", infos[0].Name)
+
+	rec, _ = serve(h, http.MethodGet, "/factories/conn", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"default": "[REDACTED]"`)
+	assert.NotContains(t, rec.Body.String(), "changeme")
+
+	rec, res := serve(h, http.MethodGet, "/factories/none", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "factory none is not registered", res["error"])
+
+	rec, _ = serve(h, http.MethodPost, "/factories", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET", rec.Header().Get("Allow"))
+}
+
+func TestInstances(t *testing.T) {
+	factory.TrackInstances(true)
+	defer factory.TrackInstances(false)
+
+	up := factory.MustCreate(factory.Config{ID: "primary", Name: "conn", Options: factory.Options{"addr": "db:5432"}})
+	factory.MustCreate(factory.Config{Name: "conn", Options: factory.Options{"addr": "cache:6379", "down": true}})
+
+	h := &admin.Handler{}
+	rec, _ := serve(h, http.MethodGet, "/instances", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var infos []admin.InstanceInfo
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &infos))
+	if assert.Len(t, infos, 2) {
+		assert.Equal(t, "db:5432", infos[0].ID)
+		assert.Equal(t, "primary", infos[0].Key)
+		assert.Equal(t, "conn", infos[0].Factory)
+		assert.True(t, infos[0].Healthy)
+		assert.False(t, infos[0].Created.IsZero())
+		assert.Equal(t, "conn", infos[1].Key)
+		assert.False(t, infos[1].Healthy)
+		assert.Equal(t, "connection refused", infos[1].Error)
+	}
+
+	assert.True(t, factory.Release(up))
+	assert.Len(t, factory.Instances(), 1)
+}
+
+func TestValidate(t *testing.T) {
+	rec, _ := serve(&admin.Handler{}, http.MethodPost, "/validate", "{}")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "validate shall be disabled by default")
+
+	h := &admin.Handler{Validate: true}
+	rec, res := serve(h, http.MethodPost, "/validate", `{"name": "conn", "options": {"addr": "db:5432"}}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, true, res["valid"])
+
+	manifest := `{
+	"instances": [
+		{"id": "a", "name": "conn", "options": {"addr": "db"}},
+		{"id": "b", "name": "conn", "options": {}},
+		{"id": "c", "name": "unknown", "options": {}}
+	],
+	"profiles": {"prod": {"a": {"addr": "db:5432"}}}
+}`
+	rec, _ = serve(h, http.MethodPost, "/validate?profile=prod", manifest)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var vr admin.ValidationResult
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &vr))
+	assert.False(t, vr.Valid)
+	if assert.Len(t, vr.Errors, 2) {
+		assert.Equal(t, "b", vr.Errors[0].Instance)
+		assert.Contains(t, vr.Errors[0].Error, "addr")
+		assert.Equal(t, "c", vr.Errors[1].Instance)
+	}
+
+	rec, res = serve(h, http.MethodPost, "/validate", `{"include": ["base.json"], "instances": []}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, res["error"], "include")
+
+	rec, _ = serve(h, http.MethodPost, "/validate", `not json`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestReload(t *testing.T) {
+	rec, _ := serve(&admin.Handler{}, http.MethodPost, "/reload", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "reload shall be disabled without hook")
+
+	calls := 0
+	h := &admin.Handler{Reload: func(context.Context) error {
+		calls++
+		if calls > 1 {
+			return errors.New("config is broken")
+		}
+		return nil
+	}}
+	rec, res := serve(h, http.MethodPost, "/reload", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", res["status"])
+
+	rec, res = serve(h, http.MethodPost, "/reload", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "config is broken", res["error"])
+
+	rec, _ = serve(h, http.MethodGet, "/reload", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,246 @@
+package factory
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Canonical return canonical JSON encoding of config.
+// Object keys are sorted, there is no insignificant whitespace and values are normalized:
+// every number is written in the same form regardless of its Go type (int 5 and float64 5.0 are both 5),
+// time.Time is written in UTC as RFC3339 with nanoseconds, time.Duration as duration string (e.g. 1h30m0s),
+// options with nil value are omitted and nil options is the same as empty options.
+// Secret values are written as is, so the result must not be logged.
+// Source position is not part of the encoding.
+func (c Config) Canonical() ([]byte, error) {
+	v, err := canonicalConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	return canonicalJSON(v)
+}
+
+// Hash return hex encoded SHA-256 of canonical encoding of config.
+// Configs that differ only in map order or numeric representation have the same hash.
+func (c Config) Hash() (string, error) {
+	data, err := c.Canonical()
+	if err != nil {
+		return "", err
+	}
+	return hashOf(data), nil
+}
+
+// Canonical return canonical JSON encoding of options (see Config.Canonical)
+func (o Options) Canonical() ([]byte, error) {
+	v, err := canonicalValue(o)
+	if err != nil {
+		return nil, err
+	}
+	return canonicalJSON(v)
+}
+
+// Hash return hex encoded SHA-256 of canonical encoding of options
+func (o Options) Hash() (string, error) {
+	data, err := o.Canonical()
+	if err != nil {
+		return "", err
+	}
+	return hashOf(data), nil
+}
+
+// canonical return canonical JSON encoding of manifest used for signing.
+// Includes are not part of the encoding since they are already resolved by the loader.
+func (m Manifest) canonical() ([]byte, error) {
+	instances := make([]interface{}, 0, len(m.Instances))
+	for _, c := range m.Instances {
+		v, err := canonicalConfig(c)
+		if err != nil {
+			return nil, instanceError(c, err)
+		}
+		instances = append(instances, v)
+	}
+	res := map[string]interface{}{
+		"instances": instances,
+	}
+	if len(m.Profiles) > 0 {
+		profiles := make(map[string]interface{}, len(m.Profiles))
+		for name, prof := range m.Profiles {
+			overrides := make(map[string]interface{}, len(prof))
+			for key, o := range prof {
+				v, err := canonicalValue(o)
+				if err != nil {
+					return nil, fmt.Errorf("profile %s: instance %s: %w", name, key, err)
+				}
+				overrides[key] = v
+			}
+			profiles[name] = overrides
+		}
+		res["profiles"] = profiles
+	}
+	return canonicalJSON(res)
+}
+
+// canonicalConfig return config as normalized generic value
+func canonicalConfig(c Config) (map[string]interface{}, error) {
+	opts, err := canonicalValue(c.Options)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = map[string]interface{}{}
+	}
+	res := map[string]interface{}{
+		"name":    c.Name,
+		"options": opts,
+	}
+	if c.ID != "" {
+		res["id"] = c.ID
+	}
+	if c.When != nil {
+		res["when"] = c.When
+	}
+	return res, nil
+}
+
+// canonicalValue convert value into normalized generic value.
+// Error contains key path of the value that cannot be encoded.
+func canonicalValue(val interface{}) (interface{}, error) {
+	val, _ = reveal(val)
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case string, bool:
+		return v, nil
+	case json.Number:
+		if f, err := v.Float64(); err == nil && !isIntegral(f) {
+			return canonicalFloat(f), nil
+		}
+		return canonicalNumber(string(v))
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return v.String(), nil
+	case Options:
+		return canonicalMap(v)
+	case map[string]interface{}:
+		return canonicalMap(v)
+	case json.Marshaler, encoding.TextMarshaler:
+		return v, nil
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(rv.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return json.Number(strconv.FormatUint(rv.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, invalidValue("number", val, nil)
+		}
+		return canonicalFloat(f), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		res := make([]interface{}, rv.Len())
+		for i := range res {
+			item, err := canonicalValue(rv.Index(i).Interface())
+			if err != nil {
+				return nil, withKey(fmt.Sprintf("[%d]", i), err)
+			}
+			res[i] = item
+		}
+		return res, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+		return canonicalMap(m)
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return canonicalValue(rv.Elem().Interface())
+	}
+	return val, nil
+}
+
+// canonicalMap normalize map values, nil values are omitted
+func canonicalMap(m map[string]interface{}) (map[string]interface{}, error) {
+	res := make(map[string]interface{}, len(m))
+	for key, val := range m {
+		v, err := canonicalValue(val)
+		if err != nil {
+			return nil, withKey(key, err)
+		}
+		if v != nil {
+			res[key] = v
+		}
+	}
+	return res, nil
+}
+
+// canonicalFloat write integral float as integer, otherwise as shortest representation
+func canonicalFloat(f float64) json.Number {
+	if isIntegral(f) && math.Abs(f) < 1<<63 {
+		return json.Number(strconv.FormatInt(int64(f), 10))
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// canonicalNumber normalize integer written as JSON number
+func canonicalNumber(s string) (json.Number, error) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return json.Number(strconv.FormatUint(u, 10)), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return "", invalidValue("number", s, nil)
+	}
+	return canonicalFloat(f), nil
+}
+
+// isIntegral return true if f has no fractional part
+func isIntegral(f float64) bool {
+	return !math.IsInf(f, 0) && f == math.Trunc(f)
+}
+
+// canonicalJSON encode value without HTML escaping and trailing newline.
+// Map keys are sorted by encoding/json.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	buf := bytes.Buffer{}
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// hashOf return hex encoded SHA-256 of data
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,69 @@
+package factory_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical(t *testing.T) {
+	var decoded factory.Options
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"port": 8080, "ratio": 0.5, "tags": ["a", "b"], "empty": null,
+		"db": {"timeout": "5s", "size": 1024}
+	}`), &decoded))
+
+	built := factory.Options{
+		"db":    factory.Options{"size": uint16(1024), "timeout": "5s"},
+		"tags":  []string{"a", "b"},
+		"ratio": float32(0.5),
+		"port":  int64(8080),
+	}
+
+	data, err := built.Canonical()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"db":{"size":1024,"timeout":"5s"},"port":8080,"ratio":0.5,"tags":["a","b"]}`, string(data))
+
+	h1, err := decoded.Hash()
+	assert.NoError(t, err)
+	h2, err := built.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, h1, h2)
+	assert.Len(t, h1, 64)
+
+	built["port"] = 8081
+	h3, err := built.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, h1, h3)
+
+	loc := time.FixedZone("WIB", 7*3600)
+	tm := time.Date(2021, 3, 4, 12, 0, 0, 0, loc)
+	data, err = factory.Options{"at": tm, "every": 90 * time.Minute, "key": factory.NewSecret("s3cr3t")}.Canonical()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"at":"2021-03-04T05:00:00Z","every":"1h30m0s","key":"s3cr3t"}`, string(data))
+
+	_, err = factory.Options{"list": []interface{}{1, map[string]interface{}{"x": func() {}}}}.Canonical()
+	assert.Error(t, err)
+}
+
+func TestConfigHash(t *testing.T) {
+	a := factory.Config{ID: "log", Name: "file", Options: factory.Options{"filename": "app.log", "mode": 420.0}}
+	b := factory.Config{ID: "log", Name: "file", Options: factory.Options{"mode": 420, "filename": "app.log"}}
+	ha, err := a.Hash()
+	assert.NoError(t, err)
+	hb, err := b.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, ha, hb)
+
+	data, err := factory.Config{Name: "file"}.Canonical()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"file","options":{}}`, string(data))
+
+	b.ID = "other"
+	hb, err = b.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, ha, hb)
+}
@@ -0,0 +1,163 @@
+// Package catalog renders browsable catalog of registered factories as Markdown or HTML.
+//
+// Typical usage, with factory packages imported:
+//
+//	catalog.Markdown(os.Stdout, "Factories", catalog.FromRegistry())
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ipsusila/factory"
+)
+
+// placeholders are example values of option types without default value
+var placeholders = map[string]interface{}{
+	"string":   "",
+	"bool":     false,
+	"int":      0,
+	"int8":     0,
+	"int16":    0,
+	"int32":    0,
+	"uint":     0,
+	"uint8":    0,
+	"uint16":   0,
+	"uint32":   0,
+	"port":     8080,
+	"float":    0.0,
+	"duration": "30s",
+	"time":     "2006-01-02T15:04:05Z",
+	"size":     "64MiB",
+	"url":      "https://example.com",
+	"ip":       "127.0.0.1",
+	"cidr":     "10.0.0.0/8",
+	"hostport": "localhost:8080",
+	"regexp":   ".*",
+	"filemode": "0644",
+	"location": "UTC",
+	"map":      "key=value",
+}
+
+// secretPlaceholder is example value of secret option
+const secretPlaceholder = factory.EncryptedPrefix + "<key>:<ciphertext>"
+
+// Entry describes a factory in the catalog
+type Entry struct {
+	Name        string
+	Description string
+	Version     string
+	Author      string
+	Repository  string
+	License     string
+	Options     []factory.OptionSpec
+
+	// Example is indented JSON of config using the factory
+	Example string
+}
+
+// FromRegistry return catalog entries of every registered factory, sorted by name
+func FromRegistry() []Entry {
+	return Entries(factory.Factories())
+}
+
+// Entries return catalog entries of given factories
+func Entries(list []*factory.Factory) []Entry {
+	res := make([]Entry, 0, len(list))
+	for _, f := range list {
+		res = append(res, NewEntry(f))
+	}
+	return res
+}
+
+// NewEntry return catalog entry of factory.
+// Default value of secret option is redacted, see Factory.Describe.
+func NewEntry(f *factory.Factory) Entry {
+	d := f.Describe()
+	return Entry{
+		Name:        d.Name,
+		Description: d.Description,
+		Version:     d.Version,
+		Author:      d.Author,
+		Repository:  d.Repository,
+		License:     d.License,
+		Options:     d.Options,
+		Example:     example(d.Name, d.Options),
+	}
+}
+
+// example return indented JSON config with default or placeholder option values
+func example(name string, specs []factory.OptionSpec) string {
+	opts := map[string]interface{}{}
+	for _, spec := range specs {
+		val := spec.Default
+		switch {
+		case spec.Secret:
+			val = secretPlaceholder
+		case val == nil:
+			val = placeholder(spec.Type)
+		}
+
+		parts := strings.Split(spec.Name, ".")
+		m := opts
+		for _, part := range parts[:len(parts)-1] {
+			nested, ok := m[part].(map[string]interface{})
+			if !ok {
+				nested = map[string]interface{}{}
+				m[part] = nested
+			}
+			m = nested
+		}
+		m[parts[len(parts)-1]] = val
+	}
+
+	buf := strings.Builder{}
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(map[string]interface{}{
+		"name":    name,
+		"options": opts,
+	})
+	if err != nil {
+		return fmt.Sprintf(`{"name": %q}`, name)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// placeholder return example value of option type
+func placeholder(typ string) interface{} {
+	if elem := strings.TrimPrefix(typ, "[]"); elem != typ {
+		return []interface{}{placeholder(elem)}
+	}
+	if val, ok := placeholders[typ]; ok {
+		return val
+	}
+	return ""
+}
+
+// defaultText return default value of option for display
+func defaultText(spec factory.OptionSpec) string {
+	switch v := spec.Default.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case factory.Secret:
+		return v.String()
+	}
+	data, err := json.Marshal(spec.Default)
+	if err != nil {
+		return fmt.Sprint(spec.Default)
+	}
+	return string(data)
+}
+
+// typeText return option type for display, empty type is string
+func typeText(spec factory.OptionSpec) string {
+	if spec.Type == "" {
+		return "string"
+	}
+	return spec.Type
+}
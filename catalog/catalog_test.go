@@ -0,0 +1,81 @@
+package catalog_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/ipsusila/factory/catalog"
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/ipsusila/factory/impl/file"
+)
+
+type store struct{}
+
+func (s *store) ID() string {
+	return "Store"
+}
+
+func init() {
+	info := factory.Info{
+		Name:        "store",
+		Description: "Key value | store",
+		Version:     "v1.2.0",
+		License:     "MIT",
+		Options: []factory.OptionSpec{
+			{Name: "db.url", Type: "url", Required: true, Description: "Database URL | DSN"},
+			{Name: "db.password", Type: "string", Default: "changeme", Secret: true},
+			{Name: "timeout", Type: "duration", Default: "5s"},
+			{Name: "tags", Type: "[]string"},
+		},
+	}
+	factory.Register("store", info, func(factory.Options) (factory.Object, error) {
+		return &store{}, nil
+	})
+}
+
+func TestEntries(t *testing.T) {
+	entries := catalog.FromRegistry()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "file", entries[0].Name)
+
+	e := entries[1]
+	assert.Equal(t, "store", e.Name)
+	assert.Equal(t, factory.NewSecret("changeme"), e.Options[1].Default, "secret default shall be redacted")
+	assert.Equal(t, `{
+  "name": "store",
+  "options": {
+    "db": {
+      "password": "enc:v1:<key>:<ciphertext>",
+      "url": "https://example.com"
+    },
+    "tags": [
+      ""
+    ],
+    "timeout": "5s"
+  }
+}`, e.Example)
+}
+
+func TestRender(t *testing.T) {
+	entries := catalog.FromRegistry()
+
+	buf := bytes.Buffer{}
+	assert.NoError(t, catalog.Markdown(&buf, "Catalog", entries))
+	md := buf.String()
+	assert.Contains(t, md, "- [store](#store) — Key value | store")
+	assert.Contains(t, md, "| `db.url` | url |  | yes | Database URL \\| DSN |")
+	assert.Contains(t, md, "| `timeout` | duration | 5s |  |  |")
+	assert.Contains(t, md, "| `db.password` | string | [REDACTED] |  |  (secret) |")
+	assert.NotContains(t, md, "changeme")
+
+	buf.Reset()
+	assert.NoError(t, catalog.HTML(&buf, "Catalog <internal>", entries))
+	html := buf.String()
+	assert.Contains(t, html, "<title>Catalog &lt;internal&gt;</title>")
+	assert.Contains(t, html, `<section id="factory-store" data-name="store">`)
+	assert.NotContains(t, html, "<link")
+	assert.NotContains(t, html, "changeme")
+	assert.Contains(t, html, "<td>[REDACTED]</td>")
+}
@@ -0,0 +1,180 @@
+package catalog
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// funcs used by templates
+var funcs = map[string]interface{}{
+	"default": defaultText,
+	"type":    typeText,
+	"anchor":  anchor,
+	"slug":    slug,
+	"cell":    cell,
+}
+
+var markdownTmpl = template.Must(template.New("markdown").Funcs(funcs).Parse(`# {{.Title}}
+{{range .Entries}}
+- [{{.Name}}](#{{slug .Name}}){{if .Description}} — {{.Description}}{{end}}
+{{- end}}
+{{range .Entries}}
+## {{.Name}}
+{{if .Description}}
+{{.Description}}
+{{end}}
+| | |
+|---|---|
+{{- if .Version}}
+| Version | {{cell .Version}} |
+{{- end}}
+{{- if .Author}}
+| Author | {{cell .Author}} |
+{{- end}}
+{{- if .License}}
+| License | {{cell .License}} |
+{{- end}}
+{{- if .Repository}}
+| Repository | {{cell .Repository}} |
+{{- end}}
+{{if .Options}}
+### Options
+
+| Name | Type | Default | Required | Description |
+|---|---|---|---|---|
+{{- range .Options}}
+| ` + "`{{.Name}}`" + ` | {{type .}} | {{cell (default .)}} | {{if .Required}}yes{{end}} | {{cell .Description}}{{if .Secret}} (secret){{end}} |
+{{- end}}
+{{else}}
+No options.
+{{end}}
+### Example
+
+` + "```json" + `
+{{.Example}}
+` + "```" + `
+{{end}}`))
+
+var htmlTmpl = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; color: #24292f; }
+nav { position: fixed; top: 0; bottom: 0; width: 16rem; overflow-y: auto; padding: 1rem; background: #f6f8fa; border-right: 1px solid #d0d7de; }
+nav input { width: 100%; box-sizing: border-box; padding: .4rem; margin-bottom: .5rem; }
+nav ul { list-style: none; padding: 0; margin: 0; }
+nav li { padding: .2rem 0; }
+main { margin-left: 18rem; padding: 1rem 2rem; max-width: 60rem; }
+section { border-bottom: 1px solid #d0d7de; padding-bottom: 1rem; }
+table { border-collapse: collapse; margin: .5rem 0; }
+th, td { border: 1px solid #d0d7de; padding: .3rem .6rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+code, pre { font-family: ui-monospace, Menlo, Consolas, monospace; }
+pre { background: #f6f8fa; padding: .8rem; overflow-x: auto; }
+.tag { font-size: .8rem; padding: 0 .3rem; border-radius: .3rem; background: #ddf4ff; }
+</style>
+</head>
+<body>
+<nav>
+<input id="filter" type="search" placeholder="Filter factories" oninput="filter(this.value)">
+<ul>
+{{- range .Entries}}
+<li data-name="{{.Name}}"><a href="#{{anchor .Name}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+</nav>
+<main>
+<h1>{{.Title}}</h1>
+{{- range .Entries}}
+<section id="{{anchor .Name}}" data-name="{{.Name}}">
+<h2>{{.Name}}{{if .Version}} <span class="tag">{{.Version}}</span>{{end}}</h2>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+<table>
+{{- if .Author}}<tr><th>Author</th><td>{{.Author}}</td></tr>{{end}}
+{{- if .License}}<tr><th>License</th><td>{{.License}}</td></tr>{{end}}
+{{- if .Repository}}<tr><th>Repository</th><td>{{.Repository}}</td></tr>{{end}}
+</table>
+<h3>Options</h3>
+{{- if .Options}}
+<table>
+<tr><th>Name</th><th>Type</th><th>Default</th><th>Required</th><th>Description</th></tr>
+{{- range .Options}}
+<tr><td><code>{{.Name}}</code></td><td>{{type .}}</td><td>{{default .}}</td><td>{{if .Required}}yes{{end}}</td><td>{{.Description}}{{if .Secret}} <span class="tag">secret</span>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No options.</p>
+{{- end}}
+<h3>Example</h3>
+<pre>{{.Example}}</pre>
+</section>
+{{- end}}
+</main>
+<script>
+function filter(q) {
+  q = q.toLowerCase();
+  document.querySelectorAll("[data-name]").forEach(function (el) {
+    el.style.display = el.dataset.name.toLowerCase().indexOf(q) >= 0 ? "" : "none";
+  });
+}
+</script>
+</body>
+</html>
+`))
+
+// page is data of catalog templates
+type page struct {
+	Title   string
+	Entries []Entry
+}
+
+// Markdown writes catalog as Markdown document
+func Markdown(w io.Writer, title string, entries []Entry) error {
+	return markdownTmpl.Execute(w, page{Title: title, Entries: entries})
+}
+
+// HTML writes catalog as self-contained HTML document, i.e. without external style or script
+func HTML(w io.Writer, title string, entries []Entry) error {
+	return htmlTmpl.Execute(w, page{Title: title, Entries: entries})
+}
+
+// anchor return link target of factory name
+func anchor(name string) string {
+	return "factory-" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, name)
+}
+
+// slug return heading anchor generated by Markdown renderers such as GitHub
+func slug(heading string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ':
+			return '-'
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_':
+			return r
+		}
+		return -1
+	}, heading)
+}
+
+// cell escape text to be written in Markdown table cell
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ipsusila/factory/catalog"
+)
+
+// runCatalog write catalog of registered factories
+func runCatalog(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("catalog", flag.ContinueOnError)
+	format := fs.String("format", "markdown", "output format, markdown or html")
+	title := fs.String("title", "Factory catalog", "catalog title")
+	output := fs.String("o", "", "output file (default standard output)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	render := catalog.Markdown
+	switch *format {
+	case "markdown", "md":
+	case "html":
+		render = catalog.HTML
+	default:
+		return fmt.Errorf("unknown format %s", *format)
+	}
+
+	w := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return render(w, *title, catalog.FromRegistry())
+}
@@ -0,0 +1,7 @@
+package main
+
+// Factories available to list, describe, validate and create.
+// Replace with blank imports of selected impl packages to build the tool with other set of factories.
+import (
+	_ "github.com/ipsusila/factory/impl/all"
+)
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ipsusila/factory"
+)
+
+// writeJSON write indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// runList print registered factories
+func runList(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print factories as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	list := factory.Factories()
+	if *asJSON {
+		infos := make([]factory.Description, len(list))
+		for i, f := range list {
+			infos[i] = f.Describe()
+		}
+		return writeJSON(stdout, infos)
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVERSION\tDESCRIPTION")
+	for _, f := range list {
+		info := f.Info()
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Name(), info.Version, info.Description)
+	}
+	return tw.Flush()
+}
+
+// runDescribe print factory information and its option schema
+func runDescribe(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("describe", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print factory as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expecting factory name")
+	}
+	f := factory.Get(fs.Arg(0))
+	if f == nil {
+		return fmt.Errorf("factory %s is not registered", fs.Arg(0))
+	}
+
+	info := f.Describe()
+	if *asJSON {
+		return writeJSON(stdout, info)
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	for _, field := range [][2]string{
+		{"Name", info.Name},
+		{"Description", info.Description},
+		{"Version", info.Version},
+		{"Author", info.Author},
+		{"Repository", info.Repository},
+		{"License", info.License},
+	} {
+		if field[1] != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", field[0], field[1])
+		}
+	}
+	if len(info.Options) == 0 {
+		fmt.Fprintln(tw, "\nNo options declared.")
+		return tw.Flush()
+	}
+
+	fmt.Fprintln(tw, "\nOPTION\tTYPE\tDEFAULT\tFLAGS\tDESCRIPTION")
+	for _, spec := range info.Options {
+		def := ""
+		if spec.Default != nil {
+			def = fmt.Sprint(spec.Default)
+		}
+		var flags []string
+		if spec.Required {
+			flags = append(flags, "required")
+		}
+		if spec.Secret {
+			flags = append(flags, "secret")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", spec.Name, spec.Type, def, strings.Join(flags, ","), spec.Description)
+	}
+	return tw.Flush()
+}
+
+// configFlags holds flags shared by validate and create
+type configFlags struct {
+	fs       *flag.FlagSet
+	single   *bool
+	profiles *string
+	keyfile  *string
+	trust    *string
+}
+
+// newConfigFlags define flags for loading and resolving configuration
+func newConfigFlags(name string) *configFlags {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	return &configFlags{
+		fs:       fs,
+		single:   fs.Bool("config", false, "file contains single config instead of manifest"),
+		profiles: fs.String("profile", "", "comma separated active profiles"),
+		keyfile:  fs.String("keyfile", "", "key file used to decrypt encrypted values"),
+		trust:    fs.String("trust", "", "trust file; if set, unsigned or tampered file is rejected"),
+	}
+}
+
+// load parse arguments, then load and resolve configuration file
+func (cf *configFlags) load(args []string) (string, []factory.Config, error) {
+	if err := cf.fs.Parse(args); err != nil {
+		return "", nil, err
+	}
+	if cf.fs.NArg() != 1 {
+		return "", nil, errors.New("expecting config file")
+	}
+	path := cf.fs.Arg(0)
+
+	k, err := factory.KeyringFromEnv()
+	if *cf.keyfile != "" {
+		k, err = factory.LoadKeyFile(*cf.keyfile)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	factory.SetKeyring(k)
+
+	if *cf.trust != "" {
+		ts, err := factory.LoadTrustFile(*cf.trust)
+		if err != nil {
+			return "", nil, err
+		}
+		factory.SetTrustStore(ts, factory.DenyUnsigned)
+	}
+
+	if *cf.single {
+		c, err := factory.LoadConfig(path)
+		if err != nil {
+			return "", nil, err
+		}
+		return path, []factory.Config{c}, nil
+	}
+
+	m, err := factory.LoadManifest(path)
+	if err != nil {
+		return "", nil, err
+	}
+	var profiles []string
+	if *cf.profiles != "" {
+		profiles = strings.Split(*cf.profiles, ",")
+	}
+	configs, err := m.Resolve(profiles...)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return path, configs, nil
+}
+
+// validateAll validate every config and print the errors
+func validateAll(stdout io.Writer, configs []factory.Config) error {
+	failed := 0
+	for _, c := range configs {
+		if err := factory.Validate(c); err != nil {
+			fmt.Fprintf(stdout, "instance %s: %v\n", c.Key(), err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d instance(s) are invalid", failed, len(configs))
+	}
+	return nil
+}
+
+// runValidate load, resolve and validate configuration without creating objects
+func runValidate(args []string, stdout io.Writer) error {
+	path, configs, err := newConfigFlags("validate").load(args)
+	if err != nil {
+		return err
+	}
+	if err := validateAll(stdout, configs); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s: %d instance(s) OK\n", path, len(configs))
+	return nil
+}
+
+// runCreate create objects from configuration and close them.
+// With -dry-run, instances that would be created are printed instead.
+func runCreate(args []string, stdout io.Writer) error {
+	cf := newConfigFlags("create")
+	dryRun := cf.fs.Bool("dry-run", false, "validate and print instances without creating them")
+	_, configs, err := cf.load(args)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		if err := validateAll(stdout, configs); err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "INSTANCE\tFACTORY\tOPTIONS")
+		for _, c := range configs {
+			data, err := json.Marshal(c.Redacted().Options)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Key(), c.Name, data)
+		}
+		return tw.Flush()
+	}
+
+	var objs []factory.Object
+	defer func() {
+		for i := len(objs) - 1; i >= 0; i-- {
+			factory.Close(objs[i])
+		}
+	}()
+	for _, c := range configs {
+		obj, err := factory.Create(c)
+		if err != nil {
+			return fmt.Errorf("instance %s: %w", c.Key(), err)
+		}
+		objs = append(objs, obj)
+		fmt.Fprintf(stdout, "created %s: %s\n", c.Key(), obj.ID())
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Command factory provides tools for working with factory configuration.
+//
+// Usage:
+//
+//	factory <command> [arguments]
+//
+// Run factory help for the list of commands.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command implemented by the tool
+type command struct {
+	summary string
+	run     func(args []string, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"list":     {"list registered factories", runList},
+	"describe": {"show factory information and option schema", runDescribe},
+	"validate": {"validate config file without creating objects", runValidate},
+	"create":   {"create objects from config file (use -dry-run to only validate)", runCreate},
+	"new":      {"create implementation package of new factory", runNew},
+	"catalog":  {"write Markdown or HTML catalog of registered factories", runCatalog},
+	"vet":      {"report factory registration mistakes in packages", runVet},
+	"keygen":   {"generate encryption key and add it to key file", runKeygen},
+	"encrypt":  {"encrypt option value", runEncrypt},
+	"rotate":   {"re-encrypt values of config file with primary key", runRotate},
+	"signkey":  {"generate configuration signing key", runSignkey},
+	"sign":     {"write detached signature of config file", runSign},
+	"verify":   {"verify detached signature of config file", runVerify},
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: factory <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		usage(os.Stdout)
+		return
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "factory: unknown command %s\n\n", os.Args[1])
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "factory %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
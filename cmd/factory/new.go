@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/ipsusila/factory"
+	"github.com/ipsusila/factory/internal/gen"
+	"github.com/ipsusila/factory/internal/scan"
+)
+
+// validName of factory created by new command
+var validName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+
+// scaffold holds values used by package templates
+type scaffold struct {
+	gen.Spec
+	ImportPath string
+	TypeName   string // Go name of the object type
+	ObjectID   string // value returned by ID method
+}
+
+// scaffoldFiles maps file name to template, {{pkg}} in name is replaced by package name
+var scaffoldFiles = map[string]*template.Template{
+	"factory.yaml": template.Must(template.New("spec").Parse(`name: {{.Name}}
+description: {{printf "%q" .Description}}
+author: {{printf "%q" .Author}}
+version: {{.Version}}
+repository: {{.Repository}}
+license: {{.License}}
+constructor: {{.Constructor}}
+# Options accepted by the factory, run go generate after editing, e.g.
+# options:
+#   - name: timeout
+#     type: duration
+#     default: 5s
+#     description: Operation timeout
+`)),
+	"{{pkg}}.go": template.Must(template.New("impl").Parse(`// Package {{.Package}} implements {{.Name}} factory.
+package {{.Package}}
+
+//go:generate go run github.com/ipsusila/factory/cmd/factorygen -spec factory.yaml
+
+import (
+	"github.com/ipsusila/factory"
+)
+
+// {{.TypeName}} is object created by {{.Name}} factory
+type {{.TypeName}} struct {
+	opts Options
+}
+
+// {{.Constructor}} create {{.TypeName}} from decoded options
+func {{.Constructor}}(o Options) (factory.Object, error) {
+	return &{{.TypeName}}{opts: o}, nil
+}
+
+// ID implements factory.Object interface
+func (o *{{.TypeName}}) ID() string {
+	return "{{.ObjectID}}"
+}
+`)),
+	"{{pkg}}_test.go": template.Must(template.New("test").Parse(`package {{.Package}}_test
+
+import (
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/ipsusila/factory/factorytest"
+
+	_ "{{.ImportPath}}"
+)
+
+func TestConformance(t *testing.T) {
+	factorytest.Run(t, "{{.Name}}", factory.Options{})
+}
+`)),
+	"example_test.go": template.Must(template.New("example").Parse(`package {{.Package}}_test
+
+import (
+	"fmt"
+
+	"github.com/ipsusila/factory"
+)
+
+func Example() {
+	obj, err := factory.Create(factory.Config{Name: "{{.Name}}"})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(obj.ID())
+	// Output: {{.ObjectID}}
+}
+`)),
+}
+
+// gitConfig return git configuration value or empty string
+func gitConfig(key string) string {
+	out, err := exec.Command("git", "config", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// runNew scaffold implementation package of new factory
+func runNew(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	dir := fs.String("dir", "", "package directory (default package name in current directory)")
+	pkg := fs.String("package", "", "package name (default factory name without punctuation)")
+	description := fs.String("description", "", "factory description")
+	author := fs.String("author", gitConfig("user.name"), "factory author")
+	version := fs.String("version", "v0.1.0", "factory version")
+	license := fs.String("license", "MIT", "factory license")
+	repository := fs.String("repository", "", "repository (default package import path)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expecting factory name")
+	}
+	name := fs.Arg(0)
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid factory name %q", name)
+	}
+
+	if *pkg == "" {
+		*pkg = strings.ToLower(strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, name))
+	}
+	if *dir == "" {
+		*dir = *pkg
+	}
+	root, module, err := scan.ModuleRoot(*dir)
+	if err != nil {
+		return err
+	}
+	if err := checkUnused(name, root); err != nil {
+		return err
+	}
+	if entries, err := os.ReadDir(*dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("directory %s is not empty", *dir)
+	}
+
+	abs, err := filepath.Abs(*dir)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return err
+	}
+	importPath := path.Join(module, filepath.ToSlash(rel))
+	if *repository == "" {
+		*repository = importPath
+	}
+	if *description == "" {
+		*description = name + " factory"
+	}
+	camel := gen.FieldName(name)
+	s := scaffold{
+		Spec: gen.Spec{
+			Package:     *pkg,
+			Name:        name,
+			Description: *description,
+			Author:      *author,
+			Version:     *version,
+			Repository:  *repository,
+			License:     *license,
+			Constructor: "new" + camel,
+		},
+		ImportPath: importPath,
+		TypeName:   strings.ToLower(camel[:1]) + camel[1:],
+		ObjectID:   camel,
+	}
+	return writeScaffold(*dir, s, stdout)
+}
+
+// checkUnused return error if factory name is registered by imported package or inside the module
+func checkUnused(name, root string) error {
+	if factory.Get(name) != nil {
+		return fmt.Errorf("factory %s is already registered", name)
+	}
+	regs, err := scan.Registrations(root)
+	if err != nil {
+		return err
+	}
+	for _, reg := range regs {
+		if reg.Name == name {
+			return fmt.Errorf("factory %s is already registered at %s", name, reg.Pos)
+		}
+	}
+	return nil
+}
+
+// writeScaffold write package files and generated code
+func writeScaffold(dir string, s scaffold, stdout io.Writer) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	files := map[string][]byte{}
+	for name, tmpl := range scaffoldFiles {
+		buf := bytes.Buffer{}
+		if err := tmpl.Execute(&buf, s); err != nil {
+			return err
+		}
+		data := buf.Bytes()
+		if strings.HasSuffix(name, ".go") {
+			src, err := format.Source(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			data = src
+		}
+		files[strings.ReplaceAll(name, "{{pkg}}", s.Package)] = data
+	}
+	src, err := gen.Generate(s.Spec, "factory.yaml")
+	if err != nil {
+		return err
+	}
+	files["factory_gen.go"] = src
+
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(stdout, "factory %s created in %s (package %s)\n", s.Name, dir, s.ImportPath)
+	return nil
+}
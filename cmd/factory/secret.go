@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ipsusila/factory"
+)
+
+// loadKeyring read keyring from key file or environment
+func loadKeyring(path string) (*factory.Keyring, error) {
+	if path != "" {
+		return factory.LoadKeyFile(path)
+	}
+	k, err := factory.KeyringFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		return nil, fmt.Errorf("key file is not specified, use -keyfile or set %s", factory.EnvKeyFile)
+	}
+	return k, nil
+}
+
+// runKeygen generate new key and append it to key file, making it primary
+func runKeygen(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	keyfile := fs.String("keyfile", os.Getenv(factory.EnvKeyFile), "key file to create or append to")
+	id := fs.String("id", "k"+time.Now().UTC().Format("20060102150405"), "key id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *keyfile == "" {
+		return errors.New("key file is not specified")
+	}
+
+	k := factory.NewKeyring()
+	if _, err := os.Stat(*keyfile); err == nil {
+		if k, err = factory.LoadKeyFile(*keyfile); err != nil {
+			return err
+		}
+	}
+	key, err := factory.GenerateKey()
+	if err != nil {
+		return err
+	}
+	if err := k.Add(*id, key); err != nil {
+		return err
+	}
+	if err := os.WriteFile(*keyfile, k.Marshal(), 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "key %s added to %s and is now primary\n", *id, *keyfile)
+	return nil
+}
+
+// runEncrypt encrypt value given as argument or read from stdin
+func runEncrypt(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("encrypt", flag.ContinueOnError)
+	keyfile := fs.String("keyfile", "", "key file, default from "+factory.EnvKeyFile+" or "+factory.EnvKeys)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	k, err := loadKeyring(*keyfile)
+	if err != nil {
+		return err
+	}
+
+	var plain string
+	if fs.NArg() > 0 {
+		plain = strings.Join(fs.Args(), " ")
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		plain = strings.TrimRight(line, "\r\n")
+	}
+
+	enc, err := k.Encrypt(plain)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, enc)
+	return nil
+}
+
+// runRotate re-encrypt every encrypted value in JSON config with primary key.
+// Output is written to stdout, or to the file itself with -w.
+// The file is not rewritten if every value is already encrypted with primary key.
+func runRotate(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("rotate", flag.ContinueOnError)
+	keyfile := fs.String("keyfile", "", "key file, default from "+factory.EnvKeyFile+" or "+factory.EnvKeys)
+	write := fs.Bool("w", false, "write result to the config file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expecting single config file")
+	}
+	k, err := loadKeyring(*keyfile)
+	if err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	// keep numbers as written, e.g. integers that do not fit in float64
+	doc := factory.Options{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	rotated, err := k.RotateOptions(doc)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	out, err := json.MarshalIndent(rotated, "", "  ")
+	if err != nil {
+		return err
+	}
+	out = append(out, '\n')
+
+	if *write {
+		if reflect.DeepEqual(doc, rotated) {
+			return nil
+		}
+		return os.WriteFile(path, out, 0644)
+	}
+	_, err = stdout.Write(out)
+	return err
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ipsusila/factory"
+)
+
+// runSignkey generate signing key and print its public key line for trust file
+func runSignkey(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("signkey", flag.ContinueOnError)
+	keyfile := fs.String("keyfile", "", "signing key file to create")
+	id := fs.String("id", "s"+time.Now().UTC().Format("20060102150405"), "key id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *keyfile == "" {
+		return errors.New("key file is not specified")
+	}
+	if _, err := os.Stat(*keyfile); err == nil {
+		return fmt.Errorf("%s already exists", *keyfile)
+	}
+
+	sk, err := factory.GenerateSigningKey(*id)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*keyfile, sk.Marshal(), 0600); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, sk.Public())
+	return nil
+}
+
+// runSign write detached signature of config or manifest file
+func runSign(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("sign", flag.ContinueOnError)
+	keyfile := fs.String("keyfile", "", "signing key file")
+	single := fs.Bool("config", false, "file contains single config instead of manifest")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expecting file to sign")
+	}
+	sk, err := factory.LoadSigningKey(*keyfile)
+	if err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+	var sig string
+	if *single {
+		c, err := factory.LoadConfig(path)
+		if err != nil {
+			return err
+		}
+		sig, err = sk.SignConfig(c)
+		if err != nil {
+			return err
+		}
+	} else {
+		m, err := factory.LoadManifest(path)
+		if err != nil {
+			return err
+		}
+		sig, err = sk.SignManifest(*m)
+		if err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(path+factory.SignatureExt, []byte(sig+"\n"), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s signed with key %s\n", path, sk.ID)
+	return nil
+}
+
+// runVerify verify detached signature of config or manifest file
+func runVerify(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	trustfile := fs.String("trust", "", "trust file containing public keys")
+	single := fs.Bool("config", false, "file contains single config instead of manifest")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expecting file to verify")
+	}
+	ts, err := factory.LoadTrustFile(*trustfile)
+	if err != nil {
+		return err
+	}
+
+	factory.SetTrustStore(ts, factory.DenyUnsigned)
+	path := fs.Arg(0)
+	if *single {
+		_, err = factory.LoadConfig(path)
+	} else {
+		_, err = factory.LoadManifest(path)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s: signature OK\n", path)
+	return nil
+}
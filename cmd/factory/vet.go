@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ipsusila/factory/internal/vet"
+)
+
+// stringList is flag that can be repeated
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// runVet report registration mistakes in packages of current module
+func runVet(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("vet", flag.ContinueOnError)
+	var configs stringList
+	fs.Var(&configs, "config", "config or manifest file whose factory names are checked (can be repeated)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	diags, err := vet.Run(".", configs, fs.Args()...)
+	if err != nil {
+		return err
+	}
+	for _, d := range diags {
+		fmt.Fprintln(stdout, d)
+	}
+	if len(diags) > 0 {
+		return fmt.Errorf("%d problem(s) found", len(diags))
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Command factorygen generates typed options struct, decode function and
+// factory registration from YAML (or JSON) spec. It is intended to be used with go generate:
+//
+//	//go:generate go run github.com/ipsusila/factory/cmd/factorygen -spec factory.yaml
+//
+// Spec contains factory information, option schema and name of constructor
+// with signature func(o Options) (factory.Object, error):
+//
+//	name: file
+//	description: Example file opener object.
+//	version: v0.1.0
+//	constructor: newFileOpener
+//	options:
+//	  - name: filename
+//	    type: string
+//	    description: Name of the file to open
+//	    required: true
+//
+// Output of factory describe -json can be used as spec as well.
+//
+// With -imports, factorygen writes package that blank imports every package
+// below given directory registering a factory, e.g. in impl/all:
+//
+//	//go:generate go run github.com/ipsusila/factory/cmd/factorygen -imports .. -o all.go
+//
+// Packages are selected using -tags build tags and -constraint is written
+// as //go:build line of the output file.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ipsusila/factory/internal/gen"
+	"github.com/ipsusila/factory/internal/scan"
+	"gopkg.in/yaml.v3"
+)
+
+func main() {
+	specFile := flag.String("spec", "factory.yaml", "spec file")
+	output := flag.String("o", "", "output file (default <spec name>_gen.go next to spec file)")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package name, if not given in spec")
+	imports := flag.String("imports", "", "directory to scan for packages registering factories")
+	tags := flag.String("tags", "", "comma separated build tags used to select packages with -imports")
+	constraint := flag.String("constraint", "", "build constraint of file generated with -imports")
+	flag.Parse()
+
+	var err error
+	if *imports != "" {
+		err = runImports(*imports, *output, *pkg, *tags, *constraint)
+	} else {
+		err = run(*specFile, *output, *pkg)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "factorygen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run generate code from spec file
+func run(specFile, output, pkg string) error {
+	data, err := os.ReadFile(specFile)
+	if err != nil {
+		return err
+	}
+	s := gen.Spec{}
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%s: %w", specFile, err)
+	}
+	if s.Package == "" {
+		s.Package = pkg
+	}
+
+	src, err := gen.Generate(s, specFile)
+	if err != nil {
+		return fmt.Errorf("%s: %w", specFile, err)
+	}
+	if output == "" {
+		base := strings.TrimSuffix(specFile, filepath.Ext(specFile))
+		output = base + "_gen.go"
+	}
+	return os.WriteFile(output, src, 0644)
+}
+
+// runImports generate package importing every package registering factories below root
+func runImports(root, output, pkg, tags, constraint string) error {
+	if output == "" {
+		output = "all.go"
+	}
+	if pkg == "" {
+		return errors.New("package is not specified")
+	}
+	var tagList []string
+	if tags != "" {
+		tagList = strings.Split(tags, ",")
+	}
+	pkgs, err := scan.Packages(root, tagList, filepath.Dir(output))
+	if err != nil {
+		return err
+	}
+	src, err := gen.Imports(pkg, constraint, pkgs)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, src, 0644)
+}
@@ -2,6 +2,18 @@ package factory
 
 // Config stores factory configuration
 type Config struct {
-	Name    string  `json:"name" toml:"name" yaml:"name" xml:"name"`
-	Options Options `json:"options" toml:"options" yaml:"options" xml:"options"`
+	ID      string     `json:"id,omitempty" toml:"id,omitempty" yaml:"id,omitempty" xml:"id,omitempty"`
+	Name    string     `json:"name" toml:"name" yaml:"name" xml:"name"`
+	Options Options    `json:"options" toml:"options" yaml:"options" xml:"options"`
+	When    *Condition `json:"when,omitempty" toml:"when,omitempty" yaml:"when,omitempty" xml:"when,omitempty"`
+
+	src *source // position in file, set by loader
+}
+
+// Key return instance identifier, i.e. ID or factory name if ID is empty
+func (c Config) Key() string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return c.Name
 }
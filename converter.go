@@ -0,0 +1,218 @@
+package factory
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// converterFunc convert raw option value into a specific type.
+// Options is passed so that conversion can reuse built-in helpers.
+type converterFunc func(o Options, val interface{}) (interface{}, error)
+
+var (
+	convertersMu sync.RWMutex
+	converters   = map[reflect.Type]converterFunc{
+		reflect.TypeOf(""): func(o Options, val interface{}) (interface{}, error) {
+			return o.asString(val)
+		},
+		reflect.TypeOf(false): func(o Options, val interface{}) (interface{}, error) {
+			return o.asBool(val)
+		},
+		reflect.TypeOf(int(0)): func(o Options, val interface{}) (interface{}, error) {
+			iv, err := o.asInt(val)
+			if err != nil {
+				return nil, err
+			}
+			if iv < math.MinInt || iv > math.MaxInt {
+				return nil, outOfRange("int", val)
+			}
+			return int(iv), nil
+		},
+		reflect.TypeOf(int64(0)): func(o Options, val interface{}) (interface{}, error) {
+			return o.asInt(val)
+		},
+		reflect.TypeOf(uint(0)): func(o Options, val interface{}) (interface{}, error) {
+			uv, err := o.asUint(val)
+			if err != nil {
+				return nil, err
+			}
+			if uv > math.MaxUint {
+				return nil, outOfRange("uint", val)
+			}
+			return uint(uv), nil
+		},
+		reflect.TypeOf(uint64(0)): func(o Options, val interface{}) (interface{}, error) {
+			return o.asUint(val)
+		},
+		reflect.TypeOf(float64(0)): func(o Options, val interface{}) (interface{}, error) {
+			return o.asFloat(val)
+		},
+		reflect.TypeOf(float32(0)): func(o Options, val interface{}) (interface{}, error) {
+			fv, err := o.asFloat(val)
+			if err != nil {
+				return nil, err
+			}
+			if math.Abs(fv) > math.MaxFloat32 {
+				return nil, outOfRange("float32", val)
+			}
+			return float32(fv), nil
+		},
+		reflect.TypeOf(time.Duration(0)): func(o Options, val interface{}) (interface{}, error) {
+			return o.asDuration(val)
+		},
+		reflect.TypeOf(time.Time{}): func(o Options, val interface{}) (interface{}, error) {
+			return o.asTime(val)
+		},
+		reflect.TypeOf(Options{}): func(o Options, val interface{}) (interface{}, error) {
+			if nested, ok := toOptions(val); ok {
+				return nested, nil
+			}
+			return nil, invalidValue("options", val, nil)
+		},
+	}
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// RegisterConverter registers conversion from raw option value into type T.
+// The converter is used by Value, Slice, Options.Decode and every other typed lookup.
+// Registering converter for a type that already has one replaces the previous converter.
+// Error returned by fn that is not *ValueError is wrapped in *ValueError, so that
+// it carries option key and value of secret option is not shown.
+// If fn is nil, it panics.
+func RegisterConverter[T any](fn func(val interface{}) (T, error)) {
+	if fn == nil {
+		panic("factory: RegisterConverter converter is nil")
+	}
+	t := typeOf[T]()
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters[t] = func(_ Options, val interface{}) (interface{}, error) {
+		res, err := fn(val)
+		var ve *ValueError
+		if err != nil && !errors.As(err, &ve) {
+			return nil, invalidValue(t.String(), val, err)
+		}
+		return res, err
+	}
+}
+
+// Convert converts raw value into type T using registered converters.
+// It can be used inside custom converter to reuse built-in conversion.
+func Convert[T any](val interface{}) (T, error) {
+	var zero T
+	if v, ok := val.(T); ok {
+		return v, nil
+	}
+	res, err := Options(nil).convert(typeOf[T](), val)
+	if err != nil {
+		return zero, err
+	}
+	return res.(T), nil
+}
+
+// typeOf return reflect.Type of T, including interface type
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// lookupConverter return converter registered for type t
+func lookupConverter(t reflect.Type) (converterFunc, bool) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	conv, ok := converters[t]
+	return conv, ok
+}
+
+// convert raw value to type t.
+// Registered converter takes precedence, then encoding.TextUnmarshaler,
+// slice conversion and finally conversion through underlying kind (e.g. enum string).
+func (o Options) convert(t reflect.Type, val interface{}) (interface{}, error) {
+	if val != nil && reflect.TypeOf(val) == t {
+		return val, nil
+	}
+	if raw, ok := reveal(val); ok {
+		res, err := o.convert(t, raw)
+		if err != nil {
+			return nil, redact(err)
+		}
+		return res, nil
+	}
+	if conv, ok := lookupConverter(t); ok {
+		return conv(o, val)
+	}
+
+	// e.g. net.IP, big.Int or custom type
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		s, err := o.asString(val)
+		if err != nil {
+			return nil, invalidValue(t.String(), val, nil)
+		}
+		ptr := reflect.New(t)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return nil, invalidValue(t.String(), val, err)
+		}
+		return ptr.Elem().Interface(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		return o.convertSlice(t, val)
+	case reflect.Ptr:
+		ev, err := o.convert(t.Elem(), val)
+		if err != nil {
+			return nil, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(reflect.ValueOf(ev))
+		return ptr.Interface(), nil
+	}
+
+	// named type, convert using its underlying kind
+	if base, ok := kindTypes[t.Kind()]; ok && base != t {
+		bv, err := o.convert(base, val)
+		if err != nil {
+			return nil, invalidValue(t.String(), val, nil)
+		}
+		return reflect.ValueOf(bv).Convert(t).Interface(), nil
+	}
+
+	return nil, invalidValue(t.String(), val,
+		fmt.Errorf("no converter registered for %s", t))
+}
+
+// convertSlice convert each element of slice/array value into slice of type t.
+// JSON array strings are accepted as well, delimited strings unless element text may contain the separator.
+func (o Options) convertSlice(t reflect.Type, val interface{}) (interface{}, error) {
+	items, ok := o.elements(val, t.Elem())
+	if !ok {
+		return nil, invalidValue(t.String(), val, nil)
+	}
+
+	res := reflect.MakeSlice(t, len(items), len(items))
+	for i, item := range items {
+		ev, err := o.convert(t.Elem(), item)
+		if err != nil {
+			return nil, withKey(fmt.Sprintf("[%d]", i), err)
+		}
+		res.Index(i).Set(reflect.ValueOf(ev))
+	}
+	return res.Interface(), nil
+}
+
+// kindTypes maps reflect kind to basic type used for named types.
+var kindTypes = map[reflect.Kind]reflect.Type{
+	reflect.String:  reflect.TypeOf(""),
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+}
@@ -0,0 +1,138 @@
+package factory_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelError
+)
+
+type mode string
+
+func init() {
+	factory.RegisterConverter(func(val interface{}) (logLevel, error) {
+		s, err := factory.Convert[string](val)
+		if err != nil {
+			return 0, err
+		}
+		switch strings.ToLower(s) {
+		case "debug":
+			return levelDebug, nil
+		case "info":
+			return levelInfo, nil
+		case "error":
+			return levelError, nil
+		}
+		return 0, fmt.Errorf("unknown level %q", s)
+	})
+}
+
+func TestConverter(t *testing.T) {
+	op := factory.Options{
+		"level":  "ERROR",
+		"levels": []string{"debug", "info"},
+		"ip":     "10.0.0.1",
+		"ips":    []interface{}{"10.0.0.1", "::1"},
+		"mode":   "fast",
+		"bad":    "verbose",
+	}
+
+	lv, err := factory.Value[logLevel](op, "level")
+	assert.Nil(t, err)
+	assert.Equal(t, levelError, lv)
+
+	lvs, err := factory.Slice[logLevel](op, "levels")
+	assert.Nil(t, err)
+	assert.Equal(t, []logLevel{levelDebug, levelInfo}, lvs)
+
+	_, err = factory.Value[logLevel](op, "bad")
+	assert.EqualError(t, err, `options.bad: invalid factory_test.logLevel "verbose": unknown level "verbose"`)
+
+	// net.IP implements encoding.TextUnmarshaler
+	ip, err := factory.Value[net.IP](op, "ip")
+	assert.Nil(t, err)
+	assert.True(t, ip.Equal(net.ParseIP("10.0.0.1")))
+
+	ips, err := factory.Slice[net.IP](op, "ips")
+	assert.Nil(t, err)
+	assert.Len(t, ips, 2)
+
+	// named type uses conversion of its underlying kind
+	m, err := factory.Value[mode](op, "mode")
+	assert.Nil(t, err)
+	assert.Equal(t, mode("fast"), m)
+
+	_, err = factory.Slice[int](factory.Options{"is": []string{"1", "x"}}, "is")
+	assert.Equal(t, `options.is[1]: invalid int "x": invalid syntax`, err.Error())
+}
+
+func TestDecode(t *testing.T) {
+	type database struct {
+		Host string
+		Port int `option:"port"`
+	}
+	type config struct {
+		Level   logLevel      `option:"level"`
+		Timeout time.Duration `option:"timeout"`
+		Peers   []net.IP      `option:"peers"`
+		DB      database      `option:"db"`
+		Retry   int           `option:"retry"`
+		Ignored string        `option:"-"`
+	}
+
+	op := factory.Options{
+		"level":   "info",
+		"timeout": "10s",
+		"peers":   []string{"192.168.1.1"},
+		"db": map[string]interface{}{
+			"host": "localhost",
+			"port": "5432",
+		},
+		"Ignored": "value",
+	}
+
+	cfg := config{Retry: 3}
+	assert.Nil(t, op.Decode(&cfg))
+	assert.Equal(t, levelInfo, cfg.Level)
+	assert.Equal(t, 10*time.Second, cfg.Timeout)
+	assert.Len(t, cfg.Peers, 1)
+	assert.Equal(t, "localhost", cfg.DB.Host)
+	assert.Equal(t, 5432, cfg.DB.Port)
+	assert.Equal(t, 3, cfg.Retry, "Missing key shall keep existing value")
+	assert.Empty(t, cfg.Ignored)
+
+	op["db"] = factory.Options{"port": "none"}
+	err := op.Decode(&cfg)
+	assert.Equal(t, `options.db.port: invalid int "none": invalid syntax`, err.Error())
+
+	assert.NotNil(t, op.Decode(cfg), "Decode requires pointer")
+}
+
+func TestConverterErrorOfSecret(t *testing.T) {
+	factory.Register("leveled", factory.Info{
+		Options: []factory.OptionSpec{{Name: "level", Type: "int", Secret: true}},
+	}, nil)
+	defer factory.Unregister("leveled")
+
+	op := factory.Get("leveled").Redact(factory.Options{"level": "hunter2"})
+	_, err := factory.Value[logLevel](op, "level")
+	var ve *factory.ValueError
+	if assert.True(t, errors.As(err, &ve)) {
+		assert.Equal(t, "level", ve.Key)
+	}
+	assert.NotContains(t, err.Error(), "hunter2")
+	assert.Equal(t, "options.level: invalid factory_test.logLevel [REDACTED]", err.Error())
+}
@@ -0,0 +1,357 @@
+package factory
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EncryptedPrefix marks encrypted option value: enc:v1:<key id>:<base64 nonce+ciphertext>
+const EncryptedPrefix = "enc:v1:"
+
+// encryptedType is ValueError.Type of value that can not be decrypted
+const encryptedType = "encrypted value"
+
+// Environment variables used by KeyringFromEnv
+const (
+	EnvKeys    = "FACTORY_KEYS"
+	EnvKeyFile = "FACTORY_KEYFILE"
+)
+
+var (
+	// ErrNoKeyring reported when encrypted value is found but no keyring is configured
+	ErrNoKeyring = errors.New("no keyring configured for encrypted value")
+
+	// ErrUnknownKey reported when encrypted value refers to key that is not in keyring
+	ErrUnknownKey = errors.New("unknown encryption key")
+)
+
+var (
+	keyringMu sync.RWMutex
+	keyring   *Keyring
+)
+
+// SetKeyring sets keyring used to decrypt encrypted option values before construction.
+func SetKeyring(k *Keyring) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	keyring = k
+}
+
+// currentKeyring return configured keyring
+func currentKeyring() *Keyring {
+	keyringMu.RLock()
+	defer keyringMu.RUnlock()
+	return keyring
+}
+
+// Keyring holds AES-GCM keys identified by id.
+// The primary (most recently added) key encrypts new values,
+// every key can decrypt values encrypted with it.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string][]byte
+	order   []string
+	primary string
+}
+
+// NewKeyring creates empty keyring
+func NewKeyring() *Keyring {
+	return &Keyring{
+		keys: map[string][]byte{},
+	}
+}
+
+// GenerateKey return random 256-bit key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Add adds key with given id and makes it primary.
+// Key must be 16, 24 or 32 bytes (AES-128, AES-192 or AES-256).
+func (k *Keyring) Add(id string, key []byte) error {
+	if id == "" || strings.ContainsAny(id, ": \t\r\n") {
+		return fmt.Errorf("invalid key id %q", id)
+	}
+	if _, err := aes.NewCipher(key); err != nil {
+		return fmt.Errorf("key %s: %w", id, err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[id]; ok {
+		return fmt.Errorf("key %s already exists", id)
+	}
+	k.keys[id] = append([]byte{}, key...)
+	k.order = append(k.order, id)
+	k.primary = id
+	return nil
+}
+
+// Primary return id of key used for encryption
+func (k *Keyring) Primary() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary
+}
+
+// IDs return key ids in the order they were added
+func (k *Keyring) IDs() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return append([]string{}, k.order...)
+}
+
+// ParseKeyring parses key file content.
+// Each line contains key id and base64 encoded key separated by whitespace,
+// empty lines and lines starting with # are ignored. The last key is primary.
+func ParseKeyring(data []byte) (*Keyring, error) {
+	k := NewKeyring()
+	if err := parseKeyLines(data, k.Add); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// LoadKeyFile reads keyring from file (see ParseKeyring)
+func LoadKeyFile(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	k, err := ParseKeyring(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return k, nil
+}
+
+// KeyringFromEnv reads keyring from FACTORY_KEYS (key file content, ";" may separate lines)
+// or from file named by FACTORY_KEYFILE. It returns nil keyring if neither is set.
+func KeyringFromEnv() (*Keyring, error) {
+	if keys, ok := os.LookupEnv(EnvKeys); ok {
+		k, err := ParseKeyring([]byte(strings.ReplaceAll(keys, ";", "\n")))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvKeys, err)
+		}
+		return k, nil
+	}
+	if path, ok := os.LookupEnv(EnvKeyFile); ok {
+		return LoadKeyFile(path)
+	}
+	return nil, nil
+}
+
+// Marshal return key file content, primary key is written last
+func (k *Keyring) Marshal() []byte {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	buf := bytes.Buffer{}
+	for _, id := range k.order {
+		if id != k.primary {
+			fmt.Fprintf(&buf, "%s %s\n", id, base64.StdEncoding.EncodeToString(k.keys[id]))
+		}
+	}
+	if k.primary != "" {
+		fmt.Fprintf(&buf, "%s %s\n", k.primary, base64.StdEncoding.EncodeToString(k.keys[k.primary]))
+	}
+	return buf.Bytes()
+}
+
+// aead return cipher for key with given id
+func (k *Keyring) aead(id string) (cipher.AEAD, error) {
+	k.mu.RLock()
+	key, ok := k.keys[id]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownKey, id)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts plain text using primary key
+func (k *Keyring) Encrypt(plain string) (string, error) {
+	id := k.Primary()
+	if id == "" {
+		return "", errors.New("keyring is empty")
+	}
+	gcm, err := k.aead(id)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plain), []byte(EncryptedPrefix+id))
+	return EncryptedPrefix + id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts value produced by Encrypt.
+// Error message never contains the plain text.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	body := strings.TrimPrefix(value, EncryptedPrefix)
+	parts := strings.SplitN(body, ":", 2)
+	if body == value || len(parts) != 2 {
+		return "", errors.New("malformed encrypted value")
+	}
+	gcm, err := k.aead(parts[0])
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	nonce, ct := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ct, []byte(EncryptedPrefix+parts[0]))
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt with key %s: %w", parts[0], err)
+	}
+	return string(plain), nil
+}
+
+// Rotate re-encrypts value using primary key.
+// Value that is already encrypted with primary key is returned as is.
+func (k *Keyring) Rotate(value string) (string, error) {
+	if strings.HasPrefix(value, EncryptedPrefix+k.Primary()+":") {
+		return value, nil
+	}
+	plain, err := k.Decrypt(value)
+	if err != nil {
+		return "", err
+	}
+	return k.Encrypt(plain)
+}
+
+// IsEncrypted return true if value is encrypted string
+func IsEncrypted(val interface{}) bool {
+	s, ok := val.(string)
+	return ok && strings.HasPrefix(s, EncryptedPrefix)
+}
+
+// DecryptOptions return copy of options where encrypted values are replaced
+// by decrypted values wrapped in Secret. Options without encrypted value are returned as is.
+func (k *Keyring) DecryptOptions(o Options) (Options, error) {
+	res, _, err := transformOptions(o, func(s string) (interface{}, error) {
+		if k == nil {
+			return nil, ErrNoKeyring
+		}
+		plain, err := k.Decrypt(s)
+		if err != nil {
+			return nil, err
+		}
+		return NewSecret(plain), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RotateOptions return copy of options where encrypted values are re-encrypted using primary key
+func (k *Keyring) RotateOptions(o Options) (Options, error) {
+	res, _, err := transformOptions(o, func(s string) (interface{}, error) {
+		return k.Rotate(s)
+	})
+	return res, err
+}
+
+// decryptOptions decrypt options using configured keyring
+func decryptOptions(o Options) (Options, error) {
+	return currentKeyring().DecryptOptions(o)
+}
+
+// transformOptions apply fn to every encrypted string, including nested options and slices.
+// Options is copied only if one of its values changed. Error contains key path of the value.
+func transformOptions(o Options, fn func(s string) (interface{}, error)) (Options, bool, error) {
+	var res Options
+	keys := make([]string, 0, len(o))
+	for key := range o {
+		keys = append(keys, key)
+	}
+	// deterministic error for the same options
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		val, changed, err := transformValue(o[key], fn)
+		if err != nil {
+			return nil, false, &ValueError{Key: joinKey(key, keyOf(err)), Type: encryptedType, Err: errorCause(err)}
+		}
+		if !changed {
+			continue
+		}
+		if res == nil {
+			res = make(Options, len(o))
+			for k, v := range o {
+				res[k] = v
+			}
+		}
+		res[key] = val
+	}
+	if res == nil {
+		return o, false, nil
+	}
+	return res, true, nil
+}
+
+// transformValue apply fn to encrypted string inside value
+func transformValue(val interface{}, fn func(s string) (interface{}, error)) (interface{}, bool, error) {
+	switch v := val.(type) {
+	case string:
+		if !IsEncrypted(v) {
+			return v, false, nil
+		}
+		res, err := fn(v)
+		return res, err == nil, err
+	case Options:
+		return transformOptions(v, fn)
+	case map[string]interface{}:
+		return transformOptions(Options(v), fn)
+	case []interface{}:
+		var res []interface{}
+		for i, item := range v {
+			nv, changed, err := transformValue(item, fn)
+			if err != nil {
+				return nil, false, &ValueError{Key: joinKey(fmt.Sprintf("[%d]", i), keyOf(err)), Err: errorCause(err)}
+			}
+			if changed && res == nil {
+				res = append([]interface{}{}, v...)
+			}
+			if changed {
+				res[i] = nv
+			}
+		}
+		if res == nil {
+			return v, false, nil
+		}
+		return res, true, nil
+	}
+	return val, false, nil
+}
+
+// keyOf return key path of ValueError
+func keyOf(err error) string {
+	var ve *ValueError
+	if errors.As(err, &ve) {
+		return ve.Key
+	}
+	return ""
+}
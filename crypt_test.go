@@ -0,0 +1,108 @@
+package factory_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+func newKeyring(t *testing.T, ids ...string) *factory.Keyring {
+	k := factory.NewKeyring()
+	for _, id := range ids {
+		key, err := factory.GenerateKey()
+		assert.Nil(t, err)
+		assert.Nil(t, k.Add(id, key))
+	}
+	return k
+}
+
+func TestKeyring(t *testing.T) {
+	k := newKeyring(t, "k1")
+	enc, err := k.Encrypt("p@ssw0rd")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(enc, "enc:v1:k1:"))
+	assert.True(t, factory.IsEncrypted(enc))
+
+	plain, err := k.Decrypt(enc)
+	assert.Nil(t, err)
+	assert.Equal(t, "p@ssw0rd", plain)
+
+	// rotate to new primary key
+	key, _ := factory.GenerateKey()
+	assert.Nil(t, k.Add("k2", key))
+	rotated, err := k.Rotate(enc)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(rotated, "enc:v1:k2:"))
+	plain, err = k.Decrypt(rotated)
+	assert.Nil(t, err)
+	assert.Equal(t, "p@ssw0rd", plain)
+
+	// round trip key file
+	k2, err := factory.ParseKeyring(k.Marshal())
+	assert.Nil(t, err)
+	assert.Equal(t, "k2", k2.Primary())
+	_, err = k2.Decrypt(enc)
+	assert.Nil(t, err)
+
+	// tampered value
+	_, err = k.Decrypt(enc[:len(enc)-4] + "AAAA")
+	assert.NotNil(t, err)
+	_, err = newKeyring(t, "k3").Decrypt(enc)
+	assert.True(t, errors.Is(err, factory.ErrUnknownKey))
+}
+
+func TestCreateWithEncryptedOptions(t *testing.T) {
+	k := newKeyring(t, "k1")
+	good, _ := k.Encrypt("5s")
+	bad, _ := k.Encrypt("15mins")
+
+	c := factory.Config{
+		Name:    "timer",
+		Options: factory.Options{"timeout": good},
+	}
+	_, err := factory.Create(c)
+	assert.True(t, errors.Is(err, factory.ErrNoKeyring))
+
+	factory.SetKeyring(k)
+	defer factory.SetKeyring(nil)
+
+	tm, err := factory.CreateAs[*timer](c)
+	assert.Nil(t, err)
+	assert.Equal(t, 5*time.Second, tm.timeout)
+	assert.Equal(t, good, c.Options["timeout"], "Config shall not be modified")
+
+	c.Options["timeout"] = bad
+	_, err = factory.Create(c)
+	assert.Equal(t, "options.timeout: invalid duration [REDACTED]", err.Error())
+
+	opts, err := k.DecryptOptions(factory.Options{"db": map[string]interface{}{"password": bad}})
+	assert.Nil(t, err)
+	db, _ := factory.Value[factory.Options](opts, "db")
+	assert.Equal(t, "15mins", db.String("password"))
+	assert.Equal(t, "[REDACTED]", fmt.Sprint(db["password"]))
+	assert.NotContains(t, fmt.Sprintf("%v %+v %#v %s", opts, opts, opts, opts), "15mins")
+}
+
+func TestValidateWithEncryptedOptions(t *testing.T) {
+	k := newKeyring(t, "k1")
+	good, _ := k.Encrypt("5s")
+	bad, _ := k.Encrypt("15mins")
+	c := factory.Config{
+		Name:    "timer",
+		Options: factory.Options{"timeout": good},
+	}
+	assert.True(t, errors.Is(factory.Validate(c), factory.ErrNoKeyring))
+
+	factory.SetKeyring(k)
+	defer factory.SetKeyring(nil)
+	assert.Nil(t, factory.Validate(c))
+
+	c.Options["timeout"] = bad
+	err := factory.Validate(c)
+	assert.Equal(t, "options.timeout: invalid duration [REDACTED]", err.Error())
+}
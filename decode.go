@@ -0,0 +1,94 @@
+package factory
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Decode stores option values into struct pointed by dst.
+// Field name is taken from `option` tag or field name (matched case-insensitively).
+// Field tagged with `option:"-"` is skipped. Field whose key does not exist keeps its value,
+// so pre-populated struct acts as default values.
+// Values are converted using registered converters, nested struct is decoded from nested options.
+func (o Options) Decode(dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("factory: Decode destination must be non-nil pointer to struct")
+	}
+	return o.decodeStruct(rv.Elem())
+}
+
+// decodeStruct decode options into struct value
+func (o Options) decodeStruct(sv reflect.Value) error {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+
+		key, val, ok := o.find(name)
+		if !ok || val == nil {
+			continue
+		}
+		if err := o.decodeField(sv.Field(i), val); err != nil {
+			return withKey(key, err)
+		}
+	}
+	return nil
+}
+
+// decodeField assign converted value into field
+func (o Options) decodeField(fv reflect.Value, val interface{}) error {
+	ft := fv.Type()
+	if ft.Kind() == reflect.Struct && !o.convertible(ft) {
+		if nested, ok := toOptions(val); ok {
+			return nested.decodeStruct(fv)
+		}
+	}
+
+	res, err := o.convert(ft, val)
+	if err != nil {
+		return err
+	}
+	fv.Set(reflect.ValueOf(res))
+	return nil
+}
+
+// convertible return true if type has registered converter or implements text unmarshaler
+func (o Options) convertible(t reflect.Type) bool {
+	if _, ok := lookupConverter(t); ok {
+		return true
+	}
+	return reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// find return option key and value with given name.
+// Exact match is preferred over case-insensitive match.
+func (o Options) find(name string) (string, interface{}, bool) {
+	if val, ok := o[name]; ok {
+		return name, val, true
+	}
+	for key, val := range o {
+		if strings.EqualFold(key, name) {
+			return key, val, true
+		}
+	}
+	return "", nil, false
+}
+
+// toOptions return nested options from map value
+func toOptions(val interface{}) (Options, bool) {
+	switch v := val.(type) {
+	case Options:
+		return v, true
+	case map[string]interface{}:
+		return Options(v), true
+	}
+	return nil, false
+}
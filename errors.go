@@ -0,0 +1,121 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrOutOfRange reported when option value does not fit in requested type
+	ErrOutOfRange = errors.New("value out of range")
+
+	// ErrMissingOption reported when required option is not specified
+	ErrMissingOption = errors.New("required option is missing")
+
+	// ErrMissingFactory reported by RequireFactories when factory is not registered
+	ErrMissingFactory = errors.New("factory is not registered")
+)
+
+// ValueError reported when option value can not be converted into requested type.
+type ValueError struct {
+	Key   string      // option key, empty if not known
+	Type  string      // requested type, e.g. int, duration
+	Value interface{} // offending value
+	Err   error       // underlying cause, if any
+}
+
+// invalidValue create ValueError for given type and value
+func invalidValue(typ string, val interface{}, err error) *ValueError {
+	return &ValueError{
+		Type:  typ,
+		Value: val,
+		Err:   err,
+	}
+}
+
+// outOfRange create ValueError for value that overflows requested type
+func outOfRange(typ string, val interface{}) *ValueError {
+	return invalidValue(typ, val, ErrOutOfRange)
+}
+
+// Error implements error interface
+func (e *ValueError) Error() string {
+	msg := fmt.Sprintf("invalid %s %s", e.Type, e.quote())
+	if e.Value == nil && e.Err != nil {
+		// nothing to show, e.g. missing option
+		msg = e.Err.Error()
+	} else if cause := e.cause(); cause != "" {
+		msg += ": " + cause
+	}
+	if e.Key != "" {
+		msg = "options." + e.Key + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap return underlying error
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
+
+// quote format offending value
+func (e *ValueError) quote() string {
+	if s, ok := e.Value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprintf("%v", e.Value)
+}
+
+// cause return underlying error message without repeating the value.
+// Cause of secret value is not shown since it may contain the value.
+func (e *ValueError) cause() string {
+	if e.Err == nil {
+		return ""
+	}
+	if _, ok := e.Value.(Secret); ok {
+		if errors.Is(e.Err, ErrOutOfRange) {
+			return ErrOutOfRange.Error()
+		}
+		return ""
+	}
+	var ne *strconv.NumError
+	if errors.As(e.Err, &ne) {
+		return ne.Err.Error()
+	}
+	return e.Err.Error()
+}
+
+// withKey return copy of error with option key prepended to its key path
+func withKey(key string, err error) error {
+	var ve *ValueError
+	if errors.As(err, &ve) {
+		cp := *ve
+		cp.Key = joinKey(key, ve.Key)
+		return &cp
+	}
+	return err
+}
+
+// joinKey join parent key with child key path, e.g. db + host = db.host, hosts + [1] = hosts[1]
+func joinKey(parent, child string) string {
+	switch {
+	case parent == "":
+		return child
+	case child == "":
+		return parent
+	case strings.HasPrefix(child, "["):
+		return parent + child
+	}
+	return parent + "." + child
+}
+
+// errorCause return underlying cause of ValueError, or err itself
+func errorCause(err error) error {
+	var ve *ValueError
+	if errors.As(err, &ve) {
+		return ve.Err
+	}
+	return err
+}
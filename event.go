@@ -0,0 +1,170 @@
+package factory
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// EventType identifies lifecycle event
+type EventType int
+
+// Lifecycle events reported to observers
+const (
+	EventRegistered EventType = iota + 1
+	EventUnregistered
+	EventCreateStarted
+	EventCreateSucceeded
+	EventCreateFailed
+	EventClosed
+	EventReloaded
+)
+
+var eventNames = map[EventType]string{
+	EventRegistered:      "registered",
+	EventUnregistered:    "unregistered",
+	EventCreateStarted:   "create-started",
+	EventCreateSucceeded: "create-succeeded",
+	EventCreateFailed:    "create-failed",
+	EventClosed:          "closed",
+	EventReloaded:        "reloaded",
+}
+
+// String return name of event type, e.g. create-failed
+func (t EventType) String() string {
+	if name, ok := eventNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Event describes something that happened in the registry
+type Event struct {
+	Type EventType
+	Time time.Time
+
+	// Factory and Info of the factory, empty for EventReloaded
+	Factory string
+	Info    Info
+
+	// Config used by Create, secret options are redacted.
+	// Config of Factory.Create has the factory name and given options.
+	Config Config
+
+	// Object created or closed
+	Object Object
+
+	// Elapsed is time spent by Create or reload
+	Elapsed time.Duration
+
+	// Err is the error of failed Create, Close or reload
+	Err error
+}
+
+// Observer receives lifecycle events.
+// OnEvent is called synchronously by the goroutine causing the event, so it must not block.
+type Observer interface {
+	OnEvent(e Event)
+}
+
+// ObserverFunc adapts function as Observer
+type ObserverFunc func(e Event)
+
+// OnEvent implements Observer
+func (fn ObserverFunc) OnEvent(e Event) {
+	fn(e)
+}
+
+// ChanObserver return observer that sends events to channel.
+// Event is dropped if the channel is full, so that slow receiver does not block creation.
+func ChanObserver(ch chan<- Event) Observer {
+	return ObserverFunc(func(e Event) {
+		select {
+		case ch <- e:
+		default:
+		}
+	})
+}
+
+// subscription of an observer
+type subscription struct {
+	o Observer
+}
+
+var (
+	observersMu sync.RWMutex
+	observers   []*subscription
+)
+
+// Subscribe adds observer that receives every lifecycle event.
+// Calling the returned function removes the observer.
+func Subscribe(o Observer) (unsubscribe func()) {
+	if o == nil {
+		panic("factory: Subscribe observer is nil")
+	}
+	sub := &subscription{o: o}
+	observersMu.Lock()
+	defer observersMu.Unlock()
+	observers = append(observers, sub)
+
+	return func() {
+		observersMu.Lock()
+		defer observersMu.Unlock()
+		for i, s := range observers {
+			if s == sub {
+				observers = append(observers[:i:i], observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// emit send event to observers
+func emit(e Event) {
+	observersMu.RLock()
+	list := observers
+	observersMu.RUnlock()
+	if len(list) == 0 {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	for _, s := range list {
+		s.o.OnEvent(e)
+	}
+}
+
+// event return event of given type about factory
+func (f *Factory) event(t EventType) Event {
+	return Event{
+		Type:    t,
+		Factory: f.name,
+		Info:    f.info,
+	}
+}
+
+// Close closes object if it implements io.Closer, stops tracking it (see Release)
+// and reports EventClosed. Factory of the event is known only if the object was tracked.
+func Close(obj Object) error {
+	var err error
+	if cl, ok := obj.(io.Closer); ok {
+		err = cl.Close()
+	}
+	e := Event{Type: EventClosed, Object: obj, Err: err}
+	if inst, ok := release(obj); ok {
+		e.Factory = inst.Factory
+		if f := Get(inst.Factory); f != nil {
+			e.Info = f.info
+		}
+	}
+	emit(e)
+	return err
+}
+
+// NotifyReload reports EventReloaded to observers.
+// It is meant to be called by application after new configuration is applied,
+// with time spent by the reload and its error, if any.
+func NotifyReload(elapsed time.Duration, err error) {
+	emit(Event{Type: EventReloaded, Elapsed: elapsed, Err: err})
+}
@@ -0,0 +1,115 @@
+package factory_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+type conn struct {
+	closed bool
+}
+
+func (c *conn) ID() string {
+	return "Conn"
+}
+
+func (c *conn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestObserver(t *testing.T) {
+	var events []factory.Event
+	unsubscribe := factory.Subscribe(factory.ObserverFunc(func(e factory.Event) {
+		events = append(events, e)
+	}))
+	defer unsubscribe()
+	factory.TrackInstances(true)
+	defer factory.TrackInstances(false)
+
+	info := factory.Info{
+		Name: "conn",
+		Options: []factory.OptionSpec{
+			{Name: "password", Type: "string", Secret: true},
+			{Name: "fail", Type: "bool"},
+		},
+	}
+	factory.Register("conn", info, func(o factory.Options) (factory.Object, error) {
+		if o.Bool("fail") {
+			return nil, errors.New("refused")
+		}
+		return &conn{}, nil
+	})
+	defer factory.Unregister("conn")
+
+	obj, err := factory.Create(factory.Config{ID: "db", Name: "conn", Options: factory.Options{"password": "s3cret"}})
+	assert.NoError(t, err)
+	_, err = factory.Create(factory.Config{Name: "conn", Options: factory.Options{"fail": true}})
+	assert.Error(t, err)
+	assert.NoError(t, factory.Close(obj))
+	assert.True(t, obj.(*conn).closed)
+	assert.Empty(t, factory.Instances(), "closed object shall be released")
+	factory.NotifyReload(time.Second, nil)
+	assert.True(t, factory.Unregister("conn"))
+	assert.False(t, factory.Unregister("conn"))
+	assert.Nil(t, factory.Get("conn"))
+
+	var types []string
+	for _, e := range events {
+		types = append(types, e.Type.String())
+		assert.False(t, e.Time.IsZero())
+	}
+	assert.Equal(t, []string{
+		"registered",
+		"create-started", "create-succeeded",
+		"create-started", "create-failed",
+		"closed", "reloaded", "unregistered",
+	}, types)
+
+	started, succeeded := events[1], events[2]
+	assert.Equal(t, "conn", started.Info.Name)
+	assert.Equal(t, "db", started.Config.ID)
+	assert.IsType(t, factory.Secret{}, started.Config.Options["password"])
+	assert.Same(t, obj, succeeded.Object)
+	assert.NotZero(t, succeeded.Elapsed)
+	assert.EqualError(t, events[4].Err, "refused")
+	assert.Equal(t, "conn", events[5].Factory)
+	assert.Equal(t, time.Second, events[6].Elapsed)
+}
+
+func TestChanObserver(t *testing.T) {
+	ch := make(chan factory.Event, 1)
+	unsubscribe := factory.Subscribe(factory.ChanObserver(ch))
+	factory.NotifyReload(0, nil)
+	factory.NotifyReload(0, errors.New("dropped"))
+	unsubscribe()
+	factory.NotifyReload(0, nil)
+
+	assert.Len(t, ch, 1)
+	e := <-ch
+	assert.Equal(t, factory.EventReloaded, e.Type)
+	assert.Nil(t, e.Err)
+}
+
+func TestCreateAsMismatchIsClosed(t *testing.T) {
+	var closed []factory.Event
+	unsubscribe := factory.Subscribe(factory.ObserverFunc(func(e factory.Event) {
+		if e.Type == factory.EventClosed {
+			closed = append(closed, e)
+		}
+	}))
+	defer unsubscribe()
+	factory.TrackInstances(true)
+	defer factory.TrackInstances(false)
+
+	_, err := factory.CreateAs[interface{ Flush() error }](factory.Config{Name: "file", Options: factory.Options{"filename": "LICENSE"}})
+	assert.Error(t, err)
+	assert.Empty(t, factory.Instances(), "Object of wrong type shall be released")
+	if assert.Len(t, closed, 1) {
+		assert.Equal(t, "file", closed[0].Factory)
+	}
+}
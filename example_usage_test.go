@@ -31,3 +31,18 @@ func Example_usage() {
 		log.Fatal(err)
 	}
 }
+
+func ExampleCreateAs() {
+	conf := factory.Config{
+		Name: "file",
+		Options: factory.Options{
+			"filename": "LICENSE",
+		},
+	}
+	// Create object and check that it implements io.ReadCloser
+	fd, err := factory.CreateAs[io.ReadCloser](conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer fd.Close()
+}
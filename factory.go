@@ -1,12 +1,19 @@
 package factory
 
 import (
+	"context"
 	"fmt"
+	"time"
 )
 
 // ConstructorFunc for creating object
 type ConstructorFunc func(args Options) (Object, error)
 
+// ContextConstructorFunc for creating object with context of the Create call.
+// Objects the constructor depends on should be created using CreateContext with the given context,
+// so that their construction is traced as child of this one.
+type ContextConstructorFunc func(ctx context.Context, args Options) (Object, error)
+
 // Object that will be created by the factory
 type Object interface {
 	ID() string
@@ -20,49 +27,107 @@ type Info struct {
 	Author      string
 	Repository  string
 	License     string
+	Options     []OptionSpec
+}
+
+// Description is printable (e.g. JSON) representation of registered factory.
+// Default values of secret options are wrapped in Secret, so they are never shown.
+type Description struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Version     string       `json:"version,omitempty"`
+	Author      string       `json:"author,omitempty"`
+	Repository  string       `json:"repository,omitempty"`
+	License     string       `json:"license,omitempty"`
+	Options     []OptionSpec `json:"options,omitempty"`
 }
 
 // Factory that responsible for creating object
 type Factory struct {
+	name string
 	info Info
-	cf   ConstructorFunc
+	cf   ContextConstructorFunc
 }
 
 // Register factory with given information and constructor.
 func Register(name string, info Info, cf ConstructorFunc) {
+	var ccf ContextConstructorFunc
+	if cf != nil {
+		ccf = func(_ context.Context, args Options) (Object, error) {
+			return cf(args)
+		}
+	}
+	RegisterContext(name, info, ccf)
+}
+
+// RegisterContext register factory whose constructor receives context of the Create call.
+func RegisterContext(name string, info Info, cf ContextConstructorFunc) {
 	f := Factory{
+		name: name,
 		info: info,
 		cf:   cf,
 	}
 	register(name, &f)
+	emit(f.event(EventRegistered))
 }
 
 // MustCreate create object using given factory name.
 // If the factory does not exists, or error, it will panic
 func MustCreate(c Config) Object {
-	f := Get(c.Name)
-	if f == nil {
-		msg := fmt.Sprintf(
-			"factory %s does not exist, do you forgot to import package?",
-			c.Name)
-		panic(msg)
-	}
-
-	obj, err := f.Create(c.Options)
+	obj, err := Create(c)
 	if err != nil {
 		panic(err)
 	}
 	return obj
 }
 
-// Create create objects using given factory name and config source
+// Create create objects using given factory name and config source.
+// If config was loaded from file, error contains position of the offending value.
 func Create(c Config) (Object, error) {
+	return CreateContext(context.Background(), c)
+}
+
+// CreateContext is like Create, the context is passed to tracer and to constructor
+// registered with RegisterContext.
+func CreateContext(ctx context.Context, c Config) (Object, error) {
+	f, err := lookup(c)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := f.create(ctx, c)
+	if err != nil {
+		return nil, c.annotate(err)
+	}
+	return obj, nil
+}
+
+// Validate checks config against options declared by the factory without creating object.
+// Encrypted values are decrypted as in Create.
+func Validate(c Config) error {
+	f, err := lookup(c)
+	if err != nil {
+		return err
+	}
+	args, err := f.prepare(c.Options)
+	if err != nil {
+		return c.annotate(err)
+	}
+	return c.annotate(f.Validate(args))
+}
+
+// lookup return factory used by config
+func lookup(c Config) (*Factory, error) {
 	f := Get(c.Name)
 	if f == nil {
-		return nil, fmt.Errorf("factory %s does not exist, do you forgot to import package?",
-			c.Name)
+		return nil, c.annotate(fmt.Errorf("factory %s does not exist, do you forgot to import package?",
+			c.Name))
 	}
-	return f.Create(c.Options)
+	return f, nil
+}
+
+// Name return name that was used to register the factory
+func (f *Factory) Name() string {
+	return f.name
 }
 
 // Info return factory information
@@ -70,10 +135,93 @@ func (f *Factory) Info() Info {
 	return f.info
 }
 
-// Create object with given configuration source
+// Describe return description of factory used by tools listing registered factories
+func (f *Factory) Describe() Description {
+	specs := make([]OptionSpec, len(f.info.Options))
+	for i, spec := range f.info.Options {
+		if spec.Secret && spec.Default != nil {
+			spec.Default = NewSecret(spec.Default)
+		}
+		specs[i] = spec
+	}
+	return Description{
+		Name:        f.name,
+		Description: f.info.Description,
+		Version:     f.info.Version,
+		Author:      f.info.Author,
+		Repository:  f.info.Repository,
+		License:     f.info.License,
+		Options:     specs,
+	}
+}
+
+// Create object with given configuration source.
+// Encrypted values are decrypted using keyring set by SetKeyring, secret options are wrapped
+// in Secret and options are validated against option specs declared in factory Info before construction.
 func (f *Factory) Create(args Options) (Object, error) {
+	return f.CreateContext(context.Background(), args)
+}
+
+// CreateContext is like Create, the context is passed to tracer and to constructor
+// registered with RegisterContext.
+func (f *Factory) CreateContext(ctx context.Context, args Options) (Object, error) {
+	return f.create(ctx, Config{Name: f.name, Options: args})
+}
+
+// create object of config instance within a span, report it to observers and metrics
+// and track it if tracking is enabled
+func (f *Factory) create(ctx context.Context, c Config) (obj Object, err error) {
+	ctx, span := currentTracer().Start(ctx, "factory.Create "+f.name,
+		Attribute{Key: AttrFactory, Value: f.name},
+		Attribute{Key: AttrVersion, Value: f.info.Version},
+		Attribute{Key: AttrInstance, Value: c.Key()},
+	)
+	defer func() {
+		if obj != nil {
+			span.SetAttributes(Attribute{Key: AttrObjectID, Value: obj.ID()})
+		}
+		span.End(err)
+	}()
+
+	e := f.event(EventCreateStarted)
+	e.Config = c
+	e.Config.Options = f.Redact(c.Options)
+	emit(e)
+
+	start := time.Now()
+	defer func() {
+		e.Elapsed = time.Since(start)
+		observeCreate(f.name, e.Elapsed, err)
+		e.Type, e.Time, e.Object, e.Err = EventCreateSucceeded, time.Time{}, obj, err
+		if err != nil {
+			e.Type = EventCreateFailed
+		}
+		emit(e)
+	}()
+
 	if f.cf == nil {
 		return nil, fmt.Errorf("constructor is not defined in factory %s", f.info.Name)
 	}
-	return f.cf(args)
+	args, err := f.prepare(c.Options)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Validate(args); err != nil {
+		return nil, err
+	}
+	obj, err = f.cf(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	track(f.name, c.Key(), obj)
+	return obj, nil
+}
+
+// prepare decrypt encrypted values and mark secret options before validation and construction
+func (f *Factory) prepare(args Options) (Options, error) {
+	args, err := decryptOptions(args)
+	if err != nil {
+		return nil, err
+	}
+	return f.Redact(args), nil
 }
@@ -1,6 +1,8 @@
 package factory_test
 
 import (
+	"context"
+	"errors"
 	"io"
 	"testing"
 
@@ -31,3 +33,37 @@ func TestFileOpenFactory(t *testing.T) {
 	// display
 
 }
+
+func TestRequireFactories(t *testing.T) {
+	factory.Register("required", factory.Info{Name: "required"}, func(factory.Options) (factory.Object, error) {
+		return nil, errors.New("not implemented")
+	})
+	defer factory.Unregister("required")
+	assert.Nil(t, factory.RequireFactories("file", "required"))
+
+	err := factory.RequireFactories("file", "redis", "cache", "redis")
+	assert.True(t, errors.Is(err, factory.ErrMissingFactory))
+	assert.Contains(t, err.Error(), "factory is not registered: redis, cache")
+}
+
+func TestTrackInstances(t *testing.T) {
+	c := factory.Config{ID: "license", Name: "file", Options: factory.Options{"filename": "LICENSE"}}
+	fo := factory.MustCreate(c)
+	fo.(io.Closer).Close()
+	assert.Empty(t, factory.Instances(), "Tracking shall be disabled by default")
+
+	factory.TrackInstances(true)
+	defer factory.TrackInstances(false)
+	fo = factory.MustCreate(c)
+	defer fo.(io.Closer).Close()
+
+	list := factory.Instances()
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, "license", list[0].Key)
+		assert.Equal(t, "file", list[0].Factory)
+		assert.Nil(t, list[0].Health(context.Background()), "Object without health check is healthy")
+	}
+	assert.True(t, factory.Release(fo))
+	assert.False(t, factory.Release(fo))
+	assert.Empty(t, factory.Instances())
+}
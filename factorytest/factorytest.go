@@ -0,0 +1,125 @@
+// Package factorytest provides conformance tests for factory implementations.
+//
+// Implementation package usually contains:
+//
+//	func TestConformance(t *testing.T) {
+//		factorytest.Run(t, "file", factory.Options{"filename": "testdata/hello.txt"})
+//	}
+package factorytest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ipsusila/factory"
+)
+
+// Run checks that factory with given name is registered, its Info and option specs are well formed,
+// object can be created from opts and required options are enforced.
+// Created objects are closed using factory.Close, so they are no longer tracked.
+func Run(t *testing.T, name string, opts factory.Options) {
+	t.Helper()
+	f := factory.Get(name)
+	if f == nil {
+		t.Fatalf("factory %s is not registered, is the package imported?", name)
+	}
+
+	t.Run("Info", func(t *testing.T) {
+		CheckInfo(t, f)
+	})
+	t.Run("Create", func(t *testing.T) {
+		obj, err := f.Create(opts)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		CheckObject(t, obj)
+	})
+	t.Run("Required", func(t *testing.T) {
+		CheckRequired(t, f, opts)
+	})
+}
+
+// CheckInfo checks that factory Info is filled and option specs are valid
+func CheckInfo(t testing.TB, f *factory.Factory) {
+	t.Helper()
+	info := f.Info()
+	if info.Name == "" {
+		t.Errorf("factory %s: Info.Name is empty", f.Name())
+	}
+	if info.Description == "" {
+		t.Errorf("factory %s: Info.Description is empty", f.Name())
+	}
+	if info.Version == "" {
+		t.Errorf("factory %s: Info.Version is empty", f.Name())
+	}
+
+	seen := map[string]bool{}
+	for i, spec := range info.Options {
+		if spec.Name == "" {
+			t.Errorf("factory %s: option[%d] has no name", f.Name(), i)
+			continue
+		}
+		if seen[spec.Name] {
+			t.Errorf("factory %s: option %s is declared twice", f.Name(), spec.Name)
+		}
+		seen[spec.Name] = true
+		if _, ok := factory.OptionType(spec.Type); !ok {
+			t.Errorf("factory %s: option %s has unknown type %s", f.Name(), spec.Name, spec.Type)
+			continue
+		}
+		if spec.Default == nil {
+			continue
+		}
+		if spec.Required {
+			t.Errorf("factory %s: required option %s has default value", f.Name(), spec.Name)
+		}
+		if err := f.Validate(factory.Options{spec.Name: spec.Default}); err != nil && !errors.Is(err, factory.ErrMissingOption) {
+			t.Errorf("factory %s: default of option %s: %v", f.Name(), spec.Name, err)
+		}
+	}
+}
+
+// CheckObject checks created object and closes it using factory.Close
+func CheckObject(t testing.TB, obj factory.Object) {
+	t.Helper()
+	if obj == nil {
+		t.Fatal("factory returned nil object without error")
+	}
+	if obj.ID() == "" {
+		t.Error("object ID is empty")
+	}
+	if err := factory.Close(obj); err != nil {
+		t.Errorf("close %s: %v", obj.ID(), err)
+	}
+}
+
+// CheckRequired checks that creating object without one of required options
+// fails with factory.ErrMissingOption
+func CheckRequired(t testing.TB, f *factory.Factory, opts factory.Options) {
+	t.Helper()
+	for _, spec := range f.Info().Options {
+		if !spec.Required {
+			continue
+		}
+		obj, err := f.Create(opts.Merge(removal(spec.Name)))
+		if err == nil {
+			factory.Close(obj)
+			t.Errorf("factory %s: object created without required option %s", f.Name(), spec.Name)
+			continue
+		}
+		if !errors.Is(err, factory.ErrMissingOption) {
+			t.Errorf("factory %s: missing option %s: expecting ErrMissingOption, got %v", f.Name(), spec.Name, err)
+		}
+	}
+}
+
+// removal return override that removes option with dotted name when merged
+func removal(name string) factory.Options {
+	parts := strings.Split(name, ".")
+	o := factory.Options{parts[len(parts)-1]: nil}
+	for i := len(parts) - 2; i >= 0; i-- {
+		o = factory.Options{parts[i]: o}
+	}
+	return o
+}
@@ -0,0 +1,36 @@
+package factorytest_test
+
+import (
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/ipsusila/factory/factorytest"
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/ipsusila/factory/impl/file"
+	_ "github.com/ipsusila/factory/impl/printer"
+)
+
+func TestFile(t *testing.T) {
+	factorytest.Run(t, "file", factory.Options{"filename": "../LICENSE"})
+}
+
+func TestPrinter(t *testing.T) {
+	factorytest.Run(t, "printer", nil)
+}
+
+func TestRunReleasesInstances(t *testing.T) {
+	factory.TrackInstances(true)
+	defer factory.TrackInstances(false)
+	closed := 0
+	unsubscribe := factory.Subscribe(factory.ObserverFunc(func(e factory.Event) {
+		if e.Type == factory.EventClosed && e.Factory == "file" {
+			closed++
+		}
+	}))
+	defer unsubscribe()
+
+	factorytest.Run(t, "file", factory.Options{"filename": "../LICENSE"})
+	assert.Empty(t, factory.Instances(), "Run shall not leave tracked instances")
+	assert.Equal(t, 1, closed)
+}
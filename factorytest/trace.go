@@ -0,0 +1,137 @@
+package factorytest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/factory"
+)
+
+// RecordedSpan is span recorded by Recorder
+type RecordedSpan struct {
+	ID         int // starts from 1 in order of start
+	Parent     int // ID of parent span, 0 for root span
+	Name       string
+	Attributes map[string]interface{}
+	Start      time.Time
+	End        time.Time // zero if span has not ended
+	Err        error
+}
+
+// Duration return time between start and end of span
+func (s RecordedSpan) Duration() time.Duration {
+	if s.End.IsZero() {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+// Recorder is factory.Tracer that keeps spans in memory
+type Recorder struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// spanKey is context key of current span of a recorder
+type spanKey struct {
+	r *Recorder
+}
+
+// recorderSpan implements factory.Span
+type recorderSpan struct {
+	r *Recorder
+	s *RecordedSpan
+}
+
+// Trace installs new Recorder as the tracer until the test finishes
+func Trace(t testing.TB) *Recorder {
+	r := &Recorder{}
+	factory.SetTracer(r)
+	t.Cleanup(func() {
+		factory.SetTracer(nil)
+	})
+	return r
+}
+
+// Start implements factory.Tracer, span is child of span started by the recorder with ctx
+func (r *Recorder) Start(ctx context.Context, name string, attrs ...factory.Attribute) (context.Context, factory.Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := &RecordedSpan{
+		ID:         len(r.spans) + 1,
+		Name:       name,
+		Attributes: map[string]interface{}{},
+		Start:      time.Now(),
+	}
+	if parent, ok := ctx.Value(spanKey{r}).(*RecordedSpan); ok {
+		s.Parent = parent.ID
+	}
+	for _, a := range attrs {
+		s.Attributes[a.Key] = a.Value
+	}
+	r.spans = append(r.spans, s)
+	return context.WithValue(ctx, spanKey{r}, s), &recorderSpan{r: r, s: s}
+}
+
+// SetAttributes implements factory.Span
+func (rs *recorderSpan) SetAttributes(attrs ...factory.Attribute) {
+	rs.r.mu.Lock()
+	defer rs.r.mu.Unlock()
+	for _, a := range attrs {
+		rs.s.Attributes[a.Key] = a.Value
+	}
+}
+
+// End implements factory.Span
+func (rs *recorderSpan) End(err error) {
+	rs.r.mu.Lock()
+	defer rs.r.mu.Unlock()
+	rs.s.End = time.Now()
+	rs.s.Err = err
+}
+
+// Spans return copy of recorded spans in order of start
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]RecordedSpan, len(r.spans))
+	for i, s := range r.spans {
+		res[i] = *s
+		res[i].Attributes = make(map[string]interface{}, len(s.Attributes))
+		for k, v := range s.Attributes {
+			res[i].Attributes[k] = v
+		}
+	}
+	return res
+}
+
+// Tree return span names indented by nesting level, one span per line.
+// Failed span is suffixed with ! and the error.
+func (r *Recorder) Tree() string {
+	spans := r.Spans()
+	depth := make(map[int]int, len(spans))
+	sb := strings.Builder{}
+	for _, s := range spans {
+		if s.Parent != 0 {
+			depth[s.ID] = depth[s.Parent] + 1
+		}
+		sb.WriteString(strings.Repeat("  ", depth[s.ID]))
+		sb.WriteString(s.Name)
+		if s.Err != nil {
+			sb.WriteString(" ! ")
+			sb.WriteString(s.Err.Error())
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Reset forgets recorded spans
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
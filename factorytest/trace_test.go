@@ -0,0 +1,78 @@
+package factorytest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/ipsusila/factory/factorytest"
+	"github.com/stretchr/testify/assert"
+)
+
+type app struct {
+	deps []factory.Object
+}
+
+func (a *app) ID() string {
+	return "App"
+}
+
+func init() {
+	info := factory.Info{Name: "app", Version: "v1.0.0"}
+	factory.RegisterContext("app", info, func(ctx context.Context, o factory.Options) (factory.Object, error) {
+		a := &app{}
+		for _, c := range []factory.Config{
+			{ID: "license", Name: "file", Options: factory.Options{"filename": "../LICENSE"}},
+			{Name: "printer"},
+			{ID: "broken", Name: "file", Options: factory.Options{}},
+		} {
+			obj, err := factory.CreateContext(ctx, c)
+			if err != nil && o.Bool("strict") {
+				for _, dep := range a.deps {
+					factory.Close(dep)
+				}
+				return nil, err
+			}
+			if obj != nil {
+				a.deps = append(a.deps, obj)
+			}
+		}
+		return a, nil
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	rec := factorytest.Trace(t)
+	obj, err := factory.CreateContext(context.Background(), factory.Config{Name: "app"})
+	assert.NoError(t, err)
+	for _, dep := range obj.(*app).deps {
+		factory.Close(dep)
+	}
+
+	assert.Equal(t, `factory.Create app
+  factory.Create file
+  factory.Create printer
+  factory.Create file ! options.filename: required option is missing
+`, rec.Tree())
+
+	spans := rec.Spans()
+	if assert.Len(t, spans, 4) {
+		assert.Equal(t, 0, spans[0].Parent)
+		assert.Equal(t, "app", spans[0].Attributes[factory.AttrFactory])
+		assert.Equal(t, "v1.0.0", spans[0].Attributes[factory.AttrVersion])
+		assert.Equal(t, "App", spans[0].Attributes[factory.AttrObjectID])
+		assert.Equal(t, spans[0].ID, spans[1].Parent)
+		assert.Equal(t, "license", spans[1].Attributes[factory.AttrInstance])
+		assert.Error(t, spans[3].Err)
+		assert.GreaterOrEqual(t, spans[0].Duration(), spans[1].Duration())
+	}
+
+	rec.Reset()
+	_, err = factory.Create(factory.Config{Name: "app", Options: factory.Options{"strict": true}})
+	assert.Error(t, err)
+	assert.Equal(t, `factory.Create app ! options.filename: required option is missing
+  factory.Create file
+  factory.Create printer
+  factory.Create file ! options.filename: required option is missing
+`, rec.Tree())
+}
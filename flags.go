@@ -0,0 +1,144 @@
+package factory
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// optionFlag implements flag.Value that stores parsed value into options
+type optionFlag struct {
+	opts Options
+	spec OptionSpec
+	typ  reflect.Type
+}
+
+// String return current value or default value
+func (v *optionFlag) String() string {
+	if v == nil || v.opts == nil {
+		return ""
+	}
+	if v.spec.Secret {
+		return ""
+	}
+	if val, ok := getPath(v.opts, v.spec.Name); ok {
+		return fmt.Sprint(val)
+	}
+	if v.spec.Default != nil {
+		return fmt.Sprint(v.spec.Default)
+	}
+	return ""
+}
+
+// Set convert flag argument and store it into options
+func (v *optionFlag) Set(s string) error {
+	val, err := v.opts.convert(v.typ, s)
+	if err != nil {
+		if cause := errorCause(err); cause != nil {
+			return cause
+		}
+		return fmt.Errorf("invalid %s", v.typ)
+	}
+	if v.spec.Secret {
+		val = NewSecret(val)
+	}
+	setPath(v.opts, v.spec.Name, val)
+	return nil
+}
+
+// IsBoolFlag allows boolean option to be specified as --name
+func (v *optionFlag) IsBoolFlag() bool {
+	return v.typ.Kind() == reflect.Bool
+}
+
+// BindSpecs defines a flag in fs for each option spec.
+// Flag name is prefix.name (or name if prefix is empty), e.g. --file.filename=LICENSE.
+// Parsed flags are stored into opts; options whose flag is not given are left untouched.
+func BindSpecs(fs *flag.FlagSet, prefix string, specs []OptionSpec, opts Options) error {
+	for _, spec := range specs {
+		t, ok := OptionType(spec.Type)
+		if !ok {
+			return fmt.Errorf("factory: option %s has unknown type %s", spec.Name, spec.Type)
+		}
+		usage := spec.Description
+		if spec.Required {
+			usage = strings.TrimSpace(usage + " (required)")
+		}
+		fv := &optionFlag{
+			opts: opts,
+			spec: spec,
+			typ:  t,
+		}
+		fs.Var(fv, joinKey(prefix, spec.Name), usage)
+	}
+	return nil
+}
+
+// BindFlags defines flags for every option declared in factory Info,
+// prefixed with the factory name, e.g. --file.filename=LICENSE.
+// Parsed values are stored into opts which then can be passed to Create.
+func (f *Factory) BindFlags(fs *flag.FlagSet, opts Options) error {
+	return BindSpecs(fs, f.name, f.info.Options, opts)
+}
+
+// FlagSet creates flag set for factory options.
+// Usage message contains factory description and option descriptions.
+func (f *Factory) FlagSet(errorHandling flag.ErrorHandling) (*flag.FlagSet, Options, error) {
+	fs := flag.NewFlagSet(f.name, errorHandling)
+	opts := Options{}
+	if err := f.BindFlags(fs, opts); err != nil {
+		return nil, nil, err
+	}
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage of %s:\n", f.name)
+		if f.info.Description != "" {
+			fmt.Fprintf(out, "  %s\n\n", f.info.Description)
+		}
+		fs.PrintDefaults()
+	}
+	return fs, opts, nil
+}
+
+// BindStruct defines flags from fields of struct v (see StructSpecs).
+// Current field values are shown as defaults, parsed values are stored into opts
+// and can be decoded back using Options.Decode.
+func BindStruct(fs *flag.FlagSet, prefix string, v interface{}, opts Options) error {
+	specs, err := StructSpecs(v)
+	if err != nil {
+		return err
+	}
+	return BindSpecs(fs, prefix, specs, opts)
+}
+
+// getPath return value with dotted key path, e.g. db.host
+func getPath(o Options, path string) (interface{}, bool) {
+	if val, ok := o[path]; ok {
+		return val, true
+	}
+	parts := strings.SplitN(path, ".", 2)
+	if len(parts) < 2 {
+		return nil, false
+	}
+	nested, ok := toOptions(o[parts[0]])
+	if !ok {
+		return nil, false
+	}
+	return getPath(nested, parts[1])
+}
+
+// setPath store value with dotted key path, creating nested options as needed
+func setPath(o Options, path string, val interface{}) {
+	parts := strings.SplitN(path, ".", 2)
+	if len(parts) < 2 {
+		o[path] = val
+		return
+	}
+	nested, ok := toOptions(o[parts[0]])
+	if !ok {
+		nested = Options{}
+		o[parts[0]] = nested
+	}
+	setPath(nested, parts[1], val)
+}
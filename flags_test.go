@@ -0,0 +1,125 @@
+package factory_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/ipsusila/factory/impl/file"
+)
+
+func TestFactoryFlagSet(t *testing.T) {
+	f := factory.Get("file")
+	fs, opts, err := f.FlagSet(flag.ContinueOnError)
+	assert.Nil(t, err)
+
+	err = fs.Parse([]string{"--file.filename=LICENSE"})
+	assert.Nil(t, err)
+	assert.Equal(t, "LICENSE", opts.String("filename"))
+
+	obj, err := f.Create(opts)
+	assert.Nil(t, err)
+	obj.(io.Closer).Close()
+
+	// usage contains description and option help
+	buf := &bytes.Buffer{}
+	fs.SetOutput(buf)
+	fs.Usage()
+	assert.Contains(t, buf.String(), f.Info().Description)
+	assert.Contains(t, buf.String(), "Name of the file to open (required)")
+}
+
+func TestBindStruct(t *testing.T) {
+	type server struct {
+		Addr    factory.HostPort `option:"addr" help:"Listen address"`
+		Timeout time.Duration    `option:"timeout" help:"Request timeout"`
+		Debug   bool             `option:"debug"`
+		DB      struct {
+			Host string `option:"host"`
+		} `option:"db"`
+	}
+
+	cfg := server{Timeout: 5 * time.Second}
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	opts := factory.Options{}
+	assert.Nil(t, factory.BindStruct(fs, "srv", &cfg, opts))
+
+	err := fs.Parse([]string{"--srv.addr=:8080", "--srv.debug", "--srv.db.host=localhost"})
+	assert.Nil(t, err)
+	assert.Nil(t, opts.Decode(&cfg))
+	assert.Equal(t, uint16(8080), cfg.Addr.Port)
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, 5*time.Second, cfg.Timeout, "Flag not given keeps default")
+	assert.Equal(t, "localhost", cfg.DB.Host)
+
+	err = fs.Parse([]string{"--srv.timeout=15mins"})
+	assert.NotNil(t, err)
+
+	specs, err := factory.StructSpecs(cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, "hostport", specs[0].Type)
+	assert.Equal(t, "duration", specs[1].Type)
+	assert.Equal(t, "db.host", specs[3].Name)
+}
+
+type lv struct{ Level int }
+
+func (l *lv) UnmarshalText(b []byte) error {
+	l.Level = len(b)
+	return nil
+}
+
+func TestStructSpecsDoesNotRegisterTypes(t *testing.T) {
+	type config struct {
+		Level lv `option:"level"`
+	}
+	specs, err := factory.StructSpecs(config{})
+	assert.Nil(t, err)
+	assert.Equal(t, "factory_test.lv", specs[0].Type)
+	_, ok := factory.OptionType("factory_test.lv")
+	assert.True(t, ok, "Derived name shall be resolvable")
+
+	// explicitly registered name wins over derived name, even if it is longer
+	factory.RegisterOptionType("verbosity-level", reflect.TypeOf(lv{}))
+	specs, err = factory.StructSpecs(config{})
+	assert.Nil(t, err)
+	assert.Equal(t, "verbosity-level", specs[0].Type)
+}
+
+func TestStructSpecsCanonicalTypes(t *testing.T) {
+	type config struct {
+		Count uint16
+		Ports []uint16
+	}
+	specs, err := factory.StructSpecs(config{})
+	assert.Nil(t, err)
+	assert.Equal(t, "uint16", specs[0].Type, "Canonical name shall be used, not port")
+	assert.Equal(t, "[]uint16", specs[1].Type)
+}
+
+func TestValidatePortSpec(t *testing.T) {
+	factory.Register("port-spec", factory.Info{
+		Options: []factory.OptionSpec{
+			{Name: "port", Type: "port"},
+			{Name: "ports", Type: "[]port"},
+			{Name: "count", Type: "uint16"},
+		},
+	}, nil)
+	defer factory.Unregister("port-spec")
+	f := factory.Get("port-spec")
+
+	assert.Nil(t, f.Validate(factory.Options{"port": 8080, "ports": "80,443", "count": 0}))
+	err := f.Validate(factory.Options{"port": 0})
+	assert.True(t, errors.Is(err, factory.ErrOutOfRange))
+	assert.EqualError(t, err, "options.port: invalid port 0: value out of range [1, 65535]")
+	err = f.Validate(factory.Options{"ports": "80,0"})
+	assert.True(t, errors.Is(err, factory.ErrOutOfRange))
+}
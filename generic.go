@@ -0,0 +1,87 @@
+package factory
+
+import (
+	"fmt"
+)
+
+// Value return option value converted to type T.
+// If the key does not exist, default value (or zero value of T) is returned without error.
+// If the value can not be converted, default value and *ValueError are returned.
+func Value[T any](o Options, key string, def ...T) (T, error) {
+	var defV T
+	if len(def) > 0 {
+		defV = def[0]
+	}
+
+	val, ok := o[key]
+	if !ok || val == nil {
+		return defV, nil
+	}
+	if v, ok := val.(T); ok {
+		return v, nil
+	}
+
+	res, err := o.convert(typeOf[T](), val)
+	if err != nil {
+		return defV, withKey(key, err)
+	}
+	return res.(T), nil
+}
+
+// Slice return option value converted to slice of T.
+// Each element is converted using the same converter as Value.
+// If the key does not exist, default items are returned without error.
+func Slice[T any](o Options, key string, def ...T) ([]T, error) {
+	val, ok := o[key]
+	if !ok || val == nil {
+		return def, nil
+	}
+	if v, ok := val.([]T); ok {
+		return v, nil
+	}
+
+	res, err := o.convert(typeOf[[]T](), val)
+	if err != nil {
+		return def, withKey(key, err)
+	}
+	return res.([]T), nil
+}
+
+// RegisterTyped register factory whose constructor return concrete type T.
+// Mismatch between constructor and Object interface is detected at compile time.
+func RegisterTyped[T Object](name string, info Info, cf func(args Options) (T, error)) {
+	Register(name, info, func(args Options) (Object, error) {
+		obj, err := cf(args)
+		if err != nil {
+			return nil, err
+		}
+		return obj, nil
+	})
+}
+
+// CreateAs create object using given config and return it as type T.
+// Error is returned if created object does not implement T.
+func CreateAs[T any](c Config) (T, error) {
+	var zero T
+	obj, err := Create(c)
+	if err != nil {
+		return zero, err
+	}
+	v, ok := obj.(T)
+	if !ok {
+		// do not leak object that caller will never see
+		Close(obj)
+		return zero, fmt.Errorf("factory %s: object %T does not implement %s",
+			c.Name, obj, typeOf[T]())
+	}
+	return v, nil
+}
+
+// MustCreateAs is like CreateAs but panics if error occurred.
+func MustCreateAs[T any](c Config) T {
+	v, err := CreateAs[T](c)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -0,0 +1,71 @@
+package factory_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/ipsusila/factory/impl/file"
+)
+
+func TestCreateAs(t *testing.T) {
+	c := factory.Config{
+		Name: "file",
+		Options: factory.Options{
+			"filename": "LICENSE",
+		},
+	}
+	fd, err := factory.CreateAs[io.ReadCloser](c)
+	assert.Nil(t, err, "Object shall implement io.ReadCloser")
+	defer fd.Close()
+
+	_, err = factory.CreateAs[fmt.Stringer](c)
+	assert.NotNil(t, err, "Object does not implement fmt.Stringer")
+	assert.Contains(t, err.Error(), "does not implement fmt.Stringer")
+
+	_, err = factory.CreateAs[io.Reader](factory.Config{Name: "-- doesn't exists --"})
+	assert.NotNil(t, err)
+}
+
+func TestValue(t *testing.T) {
+	op := factory.Options{
+		"s": "text",
+		"i": 12341,
+		"f": 10.5,
+		"d": "15m",
+		"n": "15mins",
+	}
+
+	s, err := factory.Value[string](op, "s")
+	assert.Nil(t, err)
+	assert.Equal(t, "text", s)
+
+	i, err := factory.Value[int](op, "i")
+	assert.Nil(t, err)
+	assert.Equal(t, 12341, i)
+
+	d, err := factory.Value[time.Duration](op, "d")
+	assert.Nil(t, err)
+	assert.Equal(t, 15*time.Minute, d)
+
+	// missing key return default without error
+	u, err := factory.Value[uint64](op, "missing", 7)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), u)
+
+	// invalid value return default with error
+	d, err = factory.Value[time.Duration](op, "n", time.Second)
+	assert.Equal(t, time.Second, d)
+	var ve *factory.ValueError
+	assert.True(t, errors.As(err, &ve))
+	assert.Equal(t, "n", ve.Key)
+	assert.Equal(t, `options.n: invalid duration "15mins"`, err.Error())
+
+	_, err = factory.Value[int](op, "f")
+	assert.NotNil(t, err, "fraction can not be converted to int")
+}
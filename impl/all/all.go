@@ -0,0 +1,10 @@
+// Code generated by factorygen. DO NOT EDIT.
+
+// Package all imports factory implementation packages,
+// so that importing it registers every factory listed below.
+package all
+
+import (
+	_ "github.com/ipsusila/factory/impl/file"    // file
+	_ "github.com/ipsusila/factory/impl/printer" // printer
+)
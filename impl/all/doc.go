@@ -0,0 +1,3 @@
+package all
+
+//go:generate go run github.com/ipsusila/factory/cmd/factorygen -imports .. -o all.go
@@ -0,0 +1,47 @@
+// Code generated by factorygen from factory.yaml. DO NOT EDIT.
+
+package file
+
+import (
+	"github.com/ipsusila/factory"
+)
+
+// info of file factory
+var info = factory.Info{
+	Name:        "file",
+	Description: "Example file opener object. Don't forget to close after using it.",
+	Author:      "I Putu Susila",
+	Version:     "v0.1.0",
+	Repository:  "github.com/ipsusila/factory/file",
+	License:     "MIT",
+	Options: []factory.OptionSpec{
+		{Name: "filename", Type: "string", Description: "Name of the file to open", Required: true},
+	},
+}
+
+// Options of file factory, decoded by DecodeOptions.
+type Options struct {
+	// Name of the file to open. Required.
+	Filename string `option:"filename" help:"Name of the file to open" required:"true"`
+}
+
+// defaultOptions holds default value of options
+var defaultOptions = factory.Options{}
+
+// DecodeOptions return Options decoded from factory options.
+// Option that is not given in args is set to its default value.
+func DecodeOptions(args factory.Options) (Options, error) {
+	o := Options{}
+	err := defaultOptions.Merge(args).Decode(&o)
+	return o, err
+}
+
+func init() {
+	factory.Register("file", info, func(args factory.Options) (factory.Object, error) {
+		o, err := DecodeOptions(args)
+		if err != nil {
+			return nil, err
+		}
+		return newFileOpener(o)
+	})
+}
@@ -1,5 +1,7 @@
 package file
 
+//go:generate go run github.com/ipsusila/factory/cmd/factorygen -spec factory.yaml
+
 import (
 	"os"
 
@@ -10,23 +12,9 @@ type fileOpener struct {
 	*os.File
 }
 
-var info = factory.Info{
-	Name:        "file",
-	Description: "Example file opener object. Don't forget to close after using it.",
-	Author:      "I Putu Susila",
-	Version:     "v0.1.0",
-	Repository:  "github.com/ipsusila/factory/file",
-	License:     "MIT",
-}
-
-func init() {
-	// Register factory
-	factory.Register("file", info, constructor)
-}
-
-// constructor create fileObject that open file from given configSrc
-func constructor(args factory.Options) (factory.Object, error) {
-	fd, err := os.Open(args.String("filename"))
+// newFileOpener create fileObject that open file given in options
+func newFileOpener(o Options) (factory.Object, error) {
+	fd, err := os.Open(o.Filename)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,42 @@
+// Code generated by factorygen from factory.yaml. DO NOT EDIT.
+
+package printer
+
+import (
+	"github.com/ipsusila/factory"
+)
+
+// info of printer factory
+var info = factory.Info{
+	Name:        "printer",
+	Description: "Example printer",
+	Author:      "Your Name",
+	Version:     "v0.1.0",
+	Repository:  "Link to repository (if any)",
+	License:     "Specify License (if any)",
+}
+
+// Options of printer factory, decoded by DecodeOptions.
+type Options struct {
+}
+
+// defaultOptions holds default value of options
+var defaultOptions = factory.Options{}
+
+// DecodeOptions return Options decoded from factory options.
+// Option that is not given in args is set to its default value.
+func DecodeOptions(args factory.Options) (Options, error) {
+	o := Options{}
+	err := defaultOptions.Merge(args).Decode(&o)
+	return o, err
+}
+
+func init() {
+	factory.Register("printer", info, func(args factory.Options) (factory.Object, error) {
+		o, err := DecodeOptions(args)
+		if err != nil {
+			return nil, err
+		}
+		return newPrinter(o)
+	})
+}
@@ -1,29 +1,18 @@
 package printer
 
+//go:generate go run github.com/ipsusila/factory/cmd/factorygen -spec factory.yaml
+
 import (
 	"fmt"
 
 	"github.com/ipsusila/factory"
 )
 
-var info = factory.Info{
-	Name:        "printer",
-	Description: "Example printer",
-	Author:      "Your Name",
-	Version:     "v0.1.0",
-	Repository:  "Link to repository (if any)",
-	License:     "Specify License (if any)",
-}
-
 // object to be created
 type stdoutPrinter struct{}
 
-func init() {
-	factory.Register("printer", info, constructor)
-}
-
-// constructor that will be used by factory
-func constructor(_ factory.Options) (factory.Object, error) {
+// newPrinter create printer that writes to standard output
+func newPrinter(_ Options) (factory.Object, error) {
 	return &stdoutPrinter{}, nil
 }
 
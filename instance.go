@@ -0,0 +1,120 @@
+package factory
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HealthChecker is implemented by objects that can report their health
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// Instance is an object created while instance tracking is enabled
+type Instance struct {
+	Object  Object
+	Factory string    // name of factory that created the object
+	Key     string    // instance key of config (ID or factory name)
+	Created time.Time // creation time
+}
+
+// Health return health of the object.
+// Object that does not implement HealthChecker is considered healthy.
+func (i Instance) Health(ctx context.Context) error {
+	if hc, ok := i.Object.(HealthChecker); ok {
+		return hc.Health(ctx)
+	}
+	return nil
+}
+
+var (
+	instancesMu sync.RWMutex
+	tracking    bool
+	instances   []Instance
+	live        = map[string]int{} // number of tracked instances by factory
+)
+
+// TrackInstances enables or disables tracking of created objects.
+// Tracked objects are listed by Instances until they are released,
+// so objects that are no longer used must be passed to Release.
+// Disabling tracking forgets every tracked object.
+func TrackInstances(enable bool) {
+	instancesMu.Lock()
+	tracking = enable
+	forgot := live
+	if !enable {
+		instances = nil
+		live = map[string]int{}
+	}
+	instancesMu.Unlock()
+
+	if !enable {
+		for name, n := range forgot {
+			addLive(name, -n)
+		}
+	}
+}
+
+// Instances return tracked objects, ordered by creation time
+func Instances() []Instance {
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+	res := append([]Instance{}, instances...)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Created.Before(res[j].Created)
+	})
+	return res
+}
+
+// Release stops tracking the object, e.g. after it is closed.
+// It return false if the object is not tracked.
+func Release(obj Object) bool {
+	_, ok := release(obj)
+	return ok
+}
+
+// release remove object from tracked instances
+func release(obj Object) (Instance, bool) {
+	instancesMu.Lock()
+	for i, inst := range instances {
+		if sameObject(inst.Object, obj) {
+			instances = append(instances[:i], instances[i+1:]...)
+			live[inst.Factory]--
+			instancesMu.Unlock()
+			addLive(inst.Factory, -1)
+			return inst, true
+		}
+	}
+	instancesMu.Unlock()
+	return Instance{}, false
+}
+
+// track record created object if tracking is enabled
+func track(factory, key string, obj Object) {
+	instancesMu.Lock()
+	if !tracking {
+		instancesMu.Unlock()
+		return
+	}
+	instances = append(instances, Instance{
+		Object:  obj,
+		Factory: factory,
+		Key:     key,
+		Created: time.Now(),
+	})
+	live[factory]++
+	instancesMu.Unlock()
+	addLive(factory, 1)
+}
+
+// sameObject compare objects without panic on non comparable types
+func sameObject(a, b Object) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ta := reflect.TypeOf(a)
+	return ta == reflect.TypeOf(b) && ta.Comparable() && a == b
+}
@@ -0,0 +1,405 @@
+// Package gen generates typed options struct, decode function and
+// factory registration from factory spec.
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ipsusila/factory"
+)
+
+// Spec describes factory to be generated.
+// Fields other than package, type and constructor are the same as output of factory describe -json.
+type Spec struct {
+	Package     string               `yaml:"package"`
+	Name        string               `yaml:"name"`
+	Description string               `yaml:"description"`
+	Version     string               `yaml:"version"`
+	Author      string               `yaml:"author"`
+	Repository  string               `yaml:"repository"`
+	License     string               `yaml:"license"`
+	Type        string               `yaml:"type"`
+	Constructor string               `yaml:"constructor"`
+	Options     []factory.OptionSpec `yaml:"options"`
+}
+
+// goTypes overrides Go type of option types, e.g. int is stored as int64 by Options
+var goTypes = map[string]string{
+	"int":   "int",
+	"uint":  "uint",
+	"float": "float64",
+}
+
+// initialisms written in upper case in field names
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "DB": true, "DNS": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "SQL": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true,
+	"URI": true, "URL": true, "UUID": true,
+}
+
+// field of generated struct, either option or group of nested options
+type field struct {
+	name   string // Go field name
+	key    string // option key within its parent
+	spec   *factory.OptionSpec
+	nested []*field
+}
+
+// generator writes Go source of factory
+type generator struct {
+	spec    Spec
+	source  string
+	imports map[string]bool
+	buf     bytes.Buffer
+}
+
+// Generate return formatted Go source for spec read from source file
+func Generate(s Spec, source string) ([]byte, error) {
+	if s.Name == "" {
+		return nil, errors.New("factory name is not specified")
+	}
+	if s.Package == "" {
+		return nil, errors.New("package is not specified")
+	}
+	if s.Type == "" {
+		s.Type = "Options"
+	}
+
+	g := &generator{
+		spec:    s,
+		source:  source,
+		imports: map[string]bool{"github.com/ipsusila/factory": true},
+	}
+	root, err := g.fields()
+	if err != nil {
+		return nil, err
+	}
+
+	g.info()
+	if err := g.options(root); err != nil {
+		return nil, err
+	}
+	g.defaults()
+	g.decode()
+	g.register()
+	body := append([]byte{}, g.buf.Bytes()...)
+
+	g.buf.Reset()
+	g.printf("// Code generated by factorygen from %s. DO NOT EDIT.\n\n", path.Base(source))
+	g.printf("package %s\n\n", s.Package)
+	g.printf("import (\n")
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	// standard library first, then other packages
+	sort.Slice(imports, func(i, j int) bool {
+		si, sj := !strings.Contains(imports[i], "."), !strings.Contains(imports[j], ".")
+		if si != sj {
+			return si
+		}
+		return imports[i] < imports[j]
+	})
+	for i, imp := range imports {
+		if i > 0 && !strings.Contains(imports[i-1], ".") && strings.Contains(imp, ".") {
+			g.printf("\n")
+		}
+		g.printf("%q\n", imp)
+	}
+	g.printf(")\n\n")
+	g.buf.Write(body)
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// fields build struct fields from option specs, dotted names become nested structs
+func (g *generator) fields() ([]*field, error) {
+	var root []*field
+	for i := range g.spec.Options {
+		sp := &g.spec.Options[i]
+		if sp.Name == "" {
+			return nil, fmt.Errorf("option[%d]: name is not specified", i)
+		}
+		if _, ok := factory.OptionType(sp.Type); !ok {
+			return nil, fmt.Errorf("option %s: unknown type %s", sp.Name, sp.Type)
+		}
+
+		list := &root
+		parts := strings.Split(sp.Name, ".")
+		for j, part := range parts {
+			var f *field
+			for _, cur := range *list {
+				if cur.key == part {
+					f = cur
+				}
+			}
+			last := j == len(parts)-1
+			if f == nil {
+				f = &field{name: FieldName(part), key: part}
+				*list = append(*list, f)
+			} else if last || f.spec != nil {
+				return nil, fmt.Errorf("option %s: conflicts with option %s", sp.Name,
+					strings.Join(parts[:j+1], "."))
+			}
+			if last {
+				f.spec = sp
+			}
+			list = &f.nested
+		}
+	}
+	return root, nil
+}
+
+// info writes factory Info
+func (g *generator) info() {
+	s := g.spec
+	g.printf("// info of %s factory\n", s.Name)
+	g.printf("var info = factory.Info{\n")
+	for _, kv := range [][2]string{
+		{"Name", s.Name},
+		{"Description", s.Description},
+		{"Author", s.Author},
+		{"Version", s.Version},
+		{"Repository", s.Repository},
+		{"License", s.License},
+	} {
+		if kv[1] != "" {
+			g.printf("%s: %q,\n", kv[0], kv[1])
+		}
+	}
+	if len(s.Options) > 0 {
+		g.printf("Options: []factory.OptionSpec{\n")
+		for _, sp := range s.Options {
+			g.printf("{Name: %q", sp.Name)
+			if sp.Type != "" {
+				g.printf(", Type: %q", sp.Type)
+			}
+			if sp.Default != nil {
+				g.printf(", Default: %#v", sp.Default)
+			}
+			if sp.Description != "" {
+				g.printf(", Description: %q", sp.Description)
+			}
+			if sp.Required {
+				g.printf(", Required: true")
+			}
+			if sp.Secret {
+				g.printf(", Secret: true")
+			}
+			g.printf("},\n")
+		}
+		g.printf("},\n")
+	}
+	g.printf("}\n\n")
+}
+
+// options writes options struct
+func (g *generator) options(root []*field) error {
+	g.printf("// %s of %s factory, decoded by Decode%s.\n", g.spec.Type, g.spec.Name, g.spec.Type)
+	g.printf("type %s struct {\n", g.spec.Type)
+	if err := g.structFields(root); err != nil {
+		return err
+	}
+	g.printf("}\n\n")
+	return nil
+}
+
+// structFields writes fields of struct, nested options are written as anonymous struct
+func (g *generator) structFields(fields []*field) error {
+	for i, f := range fields {
+		if i > 0 {
+			g.printf("\n")
+		}
+		if f.spec == nil {
+			g.printf("%s struct {\n", f.name)
+			if err := g.structFields(f.nested); err != nil {
+				return err
+			}
+			g.printf("} `option:%q`\n", f.key)
+			continue
+		}
+
+		sp := f.spec
+		var notes []string
+		if sp.Required {
+			notes = append(notes, "Required.")
+		}
+		if sp.Default != nil && !sp.Secret {
+			notes = append(notes, fmt.Sprintf("Default: %v.", sp.Default))
+		}
+		if sp.Secret {
+			notes = append(notes, "Secret, redacted when printed.")
+		}
+		doc := strings.TrimSpace(sp.Description)
+		if doc != "" && !strings.HasSuffix(doc, ".") {
+			doc += "."
+		}
+		if doc = strings.TrimSpace(doc + " " + strings.Join(notes, " ")); doc != "" {
+			g.printf("// %s\n", doc)
+		}
+
+		typ, err := g.goType(sp.Type)
+		if err != nil {
+			return fmt.Errorf("option %s: %w", sp.Name, err)
+		}
+		tag := fmt.Sprintf("option:%q", f.key)
+		if sp.Description != "" && !strings.Contains(sp.Description, "`") {
+			tag += fmt.Sprintf(" help:%q", sp.Description)
+		}
+		if sp.Required {
+			tag += ` required:"true"`
+		}
+		if sp.Secret {
+			tag += ` secret:"true"`
+		}
+		g.printf("%s %s `%s`\n", f.name, typ, tag)
+	}
+	return nil
+}
+
+// defaults writes default option values
+func (g *generator) defaults() {
+	defs := factory.Options{}
+	for _, sp := range g.spec.Options {
+		if sp.Default == nil {
+			continue
+		}
+		parts := strings.Split(sp.Name, ".")
+		o := defs
+		for _, part := range parts[:len(parts)-1] {
+			nested, ok := o[part].(factory.Options)
+			if !ok {
+				nested = factory.Options{}
+				o[part] = nested
+			}
+			o = nested
+		}
+		o[parts[len(parts)-1]] = sp.Default
+	}
+
+	g.printf("// default%s holds default value of options\n", g.spec.Type)
+	g.printf("var default%s = ", g.spec.Type)
+	g.optionsLiteral(defs)
+	g.printf("\n\n")
+}
+
+// optionsLiteral writes factory.Options literal with sorted keys
+func (g *generator) optionsLiteral(o factory.Options) {
+	keys := make([]string, 0, len(o))
+	for key := range o {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	g.printf("factory.Options{")
+	for _, key := range keys {
+		g.printf("\n%q: ", key)
+		if nested, ok := o[key].(factory.Options); ok {
+			g.optionsLiteral(nested)
+		} else {
+			g.printf("%#v", o[key])
+		}
+		g.printf(",")
+	}
+	if len(keys) > 0 {
+		g.printf("\n")
+	}
+	g.printf("}")
+}
+
+// decode writes decode function
+func (g *generator) decode() {
+	t := g.spec.Type
+	g.printf("// Decode%s return %s decoded from factory options.\n", t, t)
+	g.printf("// Option that is not given in args is set to its default value.\n")
+	g.printf("func Decode%s(args factory.Options) (%s, error) {\n", t, t)
+	g.printf("o := %s{}\n", t)
+	g.printf("err := default%s.Merge(args).Decode(&o)\n", t)
+	g.printf("return o, err\n")
+	g.printf("}\n")
+}
+
+// register writes Register call if constructor is specified
+func (g *generator) register() {
+	if g.spec.Constructor == "" {
+		return
+	}
+	g.printf("\nfunc init() {\n")
+	g.printf("factory.Register(%q, info, func(args factory.Options) (factory.Object, error) {\n", g.spec.Name)
+	g.printf("o, err := Decode%s(args)\n", g.spec.Type)
+	g.printf("if err != nil {\nreturn nil, err\n}\n")
+	g.printf("return %s(o)\n", g.spec.Constructor)
+	g.printf("})\n}\n")
+}
+
+// goType return Go type expression of option type
+func (g *generator) goType(name string) (string, error) {
+	elem := strings.TrimLeft(name, "[]")
+	prefix := strings.Repeat("[]", (len(name)-len(elem))/2)
+	if typ, ok := goTypes[elem]; ok {
+		return prefix + typ, nil
+	}
+	t, ok := factory.OptionType(name)
+	if !ok {
+		return "", fmt.Errorf("unknown type %s", name)
+	}
+	return g.typeExpr(t), nil
+}
+
+// typeExpr return Go type expression of t and record package to import
+func (g *generator) typeExpr(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		g.imports[t.PkgPath()] = true
+		return path.Base(t.PkgPath()) + "." + t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + g.typeExpr(t.Elem())
+	case reflect.Slice:
+		return "[]" + g.typeExpr(t.Elem())
+	case reflect.Map:
+		return "map[" + g.typeExpr(t.Key()) + "]" + g.typeExpr(t.Elem())
+	}
+	return t.String()
+}
+
+// FieldName convert option key into exported Go identifier, e.g. max_conn_id into MaxConnID
+func FieldName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	sb := strings.Builder{}
+	for _, w := range words {
+		if up := strings.ToUpper(w); initialisms[up] {
+			sb.WriteString(up)
+			continue
+		}
+		rs := []rune(w)
+		rs[0] = unicode.ToUpper(rs[0])
+		sb.WriteString(string(rs))
+	}
+	name := sb.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
@@ -0,0 +1,90 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/ipsusila/factory/internal/scan"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const testSpec = `
+package: server
+name: server
+description: HTTP server
+version: v1.0.0
+type: Config
+constructor: newServer
+options:
+  - name: listen_addr
+    type: hostport
+    default: ":8080"
+    description: Address to listen on
+  - name: timeout
+    type: duration
+    default: 5s
+  - name: max_conn
+    type: int
+  - name: tags
+    type: "[]string"
+    default: [a, b]
+  - name: db.url
+    type: url
+    required: true
+  - name: db.password
+    type: string
+    secret: true
+`
+
+func TestGenerate(t *testing.T) {
+	s := Spec{}
+	assert.NoError(t, yaml.Unmarshal([]byte(testSpec), &s))
+	src, err := Generate(s, "testdata/server.yaml")
+	assert.NoError(t, err)
+
+	code := string(src)
+	assert.Contains(t, code, "// Code generated by factorygen from server.yaml. DO NOT EDIT.")
+	assert.Contains(t, code, "import (\n\t\"net/url\"\n\t\"time\"\n\n\t\"github.com/ipsusila/factory\"\n)")
+	assert.Contains(t, code, "type Config struct {")
+	assert.Contains(t, code, "ListenAddr factory.HostPort `option:\"listen_addr\" help:\"Address to listen on\"`")
+	assert.Contains(t, code, "Timeout time.Duration `option:\"timeout\"`")
+	assert.Contains(t, code, "MaxConn int `option:\"max_conn\"`")
+	assert.Contains(t, code, "Tags []string `option:\"tags\"`")
+	assert.Contains(t, code, "URL *url.URL `option:\"url\" required:\"true\"`")
+	assert.Contains(t, code, "Password string `option:\"password\" secret:\"true\"`")
+	assert.Contains(t, code, "} `option:\"db\"`")
+	assert.Contains(t, code, `"timeout":     "5s",`)
+	assert.Contains(t, code, `"tags":        []interface{}{"a", "b"},`)
+	assert.Contains(t, code, "func DecodeConfig(args factory.Options) (Config, error) {")
+	assert.Contains(t, code, "return newServer(o)")
+
+	s.Options = append(s.Options, factory.OptionSpec{Name: "db", Type: "string"})
+	_, err = Generate(s, "server.yaml")
+	assert.EqualError(t, err, "option db: conflicts with option db")
+
+	s.Options = []factory.OptionSpec{{Name: "x", Type: "nope"}}
+	_, err = Generate(s, "server.yaml")
+	assert.EqualError(t, err, "option x: unknown type nope")
+}
+
+func TestFieldName(t *testing.T) {
+	assert.Equal(t, "MaxConnID", FieldName("max_conn_id"))
+	assert.Equal(t, "BaseURL", FieldName("base-url"))
+	assert.Equal(t, "X2fa", FieldName("2fa"))
+}
+
+func TestImports(t *testing.T) {
+	pkgs, err := scan.Packages("../../impl", nil, "../../impl/all")
+	assert.NoError(t, err)
+	assert.Len(t, pkgs, 2)
+	assert.Equal(t, []string{"file"}, pkgs[0].Names)
+
+	src, err := Imports("all", "!minimal", pkgs)
+	assert.NoError(t, err)
+	code := string(src)
+	assert.True(t, strings.HasPrefix(code, "//go:build !minimal\n\n// Code generated by factorygen. DO NOT EDIT."))
+	assert.Contains(t, code, "_ \"github.com/ipsusila/factory/impl/file\"    // file\n")
+	assert.Contains(t, code, "_ \"github.com/ipsusila/factory/impl/printer\" // printer\n")
+}
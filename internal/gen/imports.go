@@ -0,0 +1,36 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+
+	"github.com/ipsusila/factory/internal/scan"
+)
+
+// Imports return Go source of package that blank imports every package registering factories.
+// If constraint is not empty, it is written as //go:build line.
+func Imports(pkg, constraint string, pkgs []scan.Package) ([]byte, error) {
+	buf := bytes.Buffer{}
+	if constraint != "" {
+		fmt.Fprintf(&buf, "//go:build %s\n\n", constraint)
+	}
+	fmt.Fprintf(&buf, "// Code generated by factorygen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "// Package %s imports factory implementation packages,\n", pkg)
+	fmt.Fprintf(&buf, "// so that importing it registers every factory listed below.\n")
+	fmt.Fprintf(&buf, "package %s\n", pkg)
+	if len(pkgs) > 0 {
+		fmt.Fprintf(&buf, "\nimport (\n")
+		for _, p := range pkgs {
+			fmt.Fprintf(&buf, "_ %q // %s\n", p.ImportPath, strings.Join(p.Names, ", "))
+		}
+		fmt.Fprintf(&buf, ")\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return src, nil
+}
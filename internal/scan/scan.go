@@ -0,0 +1,290 @@
+// Package scan finds factory registrations in module source.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Registration of factory found in source
+type Registration struct {
+	Name string
+	Pos  token.Position
+}
+
+// FactoryPath is import path of factory package
+const FactoryPath = "github.com/ipsusila/factory"
+
+// registerFuncs are functions whose first argument is factory name
+var registerFuncs = map[string]bool{
+	"Register":        true,
+	"RegisterTyped":   true,
+	"RegisterContext": true,
+}
+
+// ModuleRoot return directory containing go.mod and the module path,
+// starting from dir and walking up to the file system root.
+func ModuleRoot(dir string) (string, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			return dir, modulePath(data), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", errors.New("go.mod not found")
+		}
+		dir = parent
+	}
+}
+
+// modulePath return module path declared in go.mod
+func modulePath(data []byte) string {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) >= 2 && fields[0] == "module" {
+			if path, err := strconv.Unquote(fields[1]); err == nil {
+				return path
+			}
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// Registrations return factories registered with a constant name in Go files below root,
+// as well as names declared in factorygen spec files (factory.yaml).
+// Hidden directories, testdata and vendor are skipped.
+func Registrations(root string) ([]Registration, error) {
+	var res []Registration
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || name == "testdata" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case strings.HasSuffix(name, ".go"):
+			regs, err := File(fset, path)
+			if err != nil {
+				return err
+			}
+			res = append(res, regs...)
+		case name == "factory.yaml":
+			reg, err := specRegistration(path)
+			if err != nil {
+				return err
+			}
+			if reg.Name != "" {
+				res = append(res, reg)
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+// File return factories registered with a constant name in Go file.
+// Calls are matched by function name, Register, RegisterTyped or RegisterContext,
+// of the factory package imported by the file (under any name, or dot imported).
+func File(fset *token.FileSet, path string) ([]Registration, error) {
+	f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	quals, dot := factoryImports(f)
+	if len(quals) == 0 && !dot {
+		return nil, nil
+	}
+
+	var res []Registration
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		qual, name := funcName(call.Fun)
+		if !registerFuncs[name] || (qual == "" && !dot) || (qual != "" && !quals[qual]) {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		if name, err := strconv.Unquote(lit.Value); err == nil {
+			res = append(res, Registration{Name: name, Pos: fset.Position(lit.Pos())})
+		}
+		return true
+	})
+	return res, nil
+}
+
+// factoryImports return names the factory package is imported as in file,
+// and whether it is dot imported
+func factoryImports(f *ast.File) (map[string]bool, bool) {
+	quals := map[string]bool{}
+	dot := false
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || path != FactoryPath {
+			continue
+		}
+		switch {
+		case imp.Name == nil:
+			quals[pathpkg.Base(path)] = true
+		case imp.Name.Name == ".":
+			dot = true
+		case imp.Name.Name != "_":
+			quals[imp.Name.Name] = true
+		}
+	}
+	return quals, dot
+}
+
+// funcName return qualifier and name of called function, e.g. factory and Register
+// for factory.Register, or factory and RegisterTyped for factory.RegisterTyped[T]
+func funcName(fun ast.Expr) (string, string) {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return "", f.Name
+	case *ast.SelectorExpr:
+		if x, ok := f.X.(*ast.Ident); ok {
+			return x.Name, f.Sel.Name
+		}
+	case *ast.IndexExpr:
+		return funcName(f.X)
+	}
+	return "", ""
+}
+
+// specRegistration return factory name declared in spec file
+func specRegistration(path string) (Registration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Registration{}, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return Registration{}, err
+	}
+	if len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+		return Registration{}, nil
+	}
+	m := node.Content[0]
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == "name" {
+			v := m.Content[i+1]
+			return Registration{
+				Name: v.Value,
+				Pos:  token.Position{Filename: path, Line: v.Line, Column: v.Column},
+			}, nil
+		}
+	}
+	return Registration{}, nil
+}
+
+// Package registering factories
+type Package struct {
+	ImportPath string
+	Dir        string
+	Names      []string // registered factory names
+}
+
+// Packages return importable packages below root that register factories with a constant name,
+// sorted by import path. Files are selected using build context with given build tags,
+// test files, main packages and directories in exclude are skipped.
+func Packages(root string, tags []string, exclude ...string) ([]Package, error) {
+	modRoot, module, err := ModuleRoot(root)
+	if err != nil {
+		return nil, err
+	}
+	skip := map[string]bool{}
+	for _, dir := range exclude {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		skip[abs] = true
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = append(append([]string{}, ctx.BuildTags...), tags...)
+	fset := token.NewFileSet()
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Package
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		name := d.Name()
+		if path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+			name == "testdata" || name == "vendor") || skip[path] {
+			return filepath.SkipDir
+		}
+
+		bp, err := ctx.ImportDir(path, 0)
+		if err != nil || bp.Name == "main" {
+			// no buildable Go files for given tags
+			return nil
+		}
+		pkg := Package{Dir: path}
+		for _, file := range bp.GoFiles {
+			regs, err := File(fset, filepath.Join(path, file))
+			if err != nil {
+				return err
+			}
+			for _, r := range regs {
+				pkg.Names = append(pkg.Names, r.Name)
+			}
+		}
+		if len(pkg.Names) == 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(modRoot, path)
+		if err != nil {
+			return err
+		}
+		pkg.ImportPath = pathpkg.Join(module, filepath.ToSlash(rel))
+		res = append(res, pkg)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ImportPath < res[j].ImportPath
+	})
+	return res, nil
+}
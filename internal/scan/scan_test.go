@@ -0,0 +1,156 @@
+package scan_test
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ipsusila/factory/internal/scan"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFiles creates files below temporary directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestFile(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "default import",
+			src: `package a
+import "github.com/ipsusila/factory"
+func init() { factory.Register("a", factory.Info{}, nil) }`,
+			want: []string{"a"},
+		},
+		{
+			name: "aliased import",
+			src: `package a
+import fx "github.com/ipsusila/factory"
+func init() {
+	fx.Register("a", fx.Info{}, nil)
+	factory.Register("not-factory", nil, nil)
+}`,
+			want: []string{"a"},
+		},
+		{
+			name: "dot import",
+			src: `package a
+import . "github.com/ipsusila/factory"
+func init() { Register("a", Info{}, nil) }`,
+			want: []string{"a"},
+		},
+		{
+			name: "unrelated Register",
+			src: `package a
+import "example.com/plugin"
+func init() {
+	plugin.Register("hook", nil)
+	Register("local")
+}`,
+		},
+		{
+			name: "unrelated Register next to factory import",
+			src: `package a
+import (
+	"github.com/ipsusila/factory"
+	"example.com/plugin"
+)
+func init() {
+	plugin.Register("hook", nil)
+	factory.Register("a", factory.Info{}, nil)
+}`,
+			want: []string{"a"},
+		},
+		{
+			name: "RegisterTyped and RegisterContext",
+			src: `package a
+import "github.com/ipsusila/factory"
+func init() {
+	factory.RegisterTyped[*obj]("typed", factory.Info{}, newObj)
+	factory.RegisterTyped("inferred", factory.Info{}, newObj)
+	factory.RegisterContext("ctx", factory.Info{}, nil)
+	factory.Register(name, factory.Info{}, nil)
+}`,
+			want: []string{"typed", "inferred", "ctx"},
+		},
+		{
+			name: "blank import",
+			src: `package a
+import _ "github.com/ipsusila/factory"
+func init() { factory.Register("a", nil, nil) }`,
+		},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "a.go")
+			assert.NoError(t, os.WriteFile(path, []byte(tt.src), 0644))
+			regs, err := scan.File(token.NewFileSet(), path)
+			assert.NoError(t, err)
+			var names []string
+			for _, r := range regs {
+				names = append(names, r.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestRegistrations(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod": "module example.com/app\n",
+		"a/a.go": `package a
+import "github.com/ipsusila/factory"
+func init() { factory.Register("a", factory.Info{}, nil) }`,
+		"b/factory.yaml":       "package: b\nname: b\n",
+		"testdata/skip.go":     `package skip; import "github.com/ipsusila/factory"; func init() { factory.Register("skip", factory.Info{}, nil) }`,
+		"plugin/plugin.go":     `package plugin; func Register(name string) {}; func init() { Register("hook") }`,
+		"tagged/tagged.go":     "//go:build extra\n\npackage tagged\nimport \"github.com/ipsusila/factory\"\nfunc init() { factory.Register(\"tagged\", factory.Info{}, nil) }",
+		"cmd/main.go":          `package main; import "github.com/ipsusila/factory"; func main() { factory.Register("main", factory.Info{}, nil) }`,
+		"a/a_test.go":          `package a; import "github.com/ipsusila/factory"; func init() { factory.Register("test", factory.Info{}, nil) }`,
+		"excluded/excluded.go": `package excluded; import "github.com/ipsusila/factory"; func init() { factory.Register("excluded", factory.Info{}, nil) }`,
+	})
+
+	regs, err := scan.Registrations(dir)
+	assert.NoError(t, err)
+	names := map[string]token.Position{}
+	for _, r := range regs {
+		names[r.Name] = r.Pos
+	}
+	assert.Contains(t, names, "a")
+	assert.Contains(t, names, "b")
+	assert.Equal(t, 2, names["b"].Line)
+	assert.NotContains(t, names, "skip")
+	assert.NotContains(t, names, "hook")
+
+	root, module, err := scan.ModuleRoot(filepath.Join(dir, "a"))
+	assert.NoError(t, err)
+	assert.Equal(t, dir, root)
+	assert.Equal(t, "example.com/app", module)
+
+	pkgs, err := scan.Packages(dir, nil, filepath.Join(dir, "excluded"))
+	assert.NoError(t, err)
+	if assert.Len(t, pkgs, 1) {
+		assert.Equal(t, "example.com/app/a", pkgs[0].ImportPath)
+		assert.Equal(t, []string{"a"}, pkgs[0].Names)
+	}
+
+	pkgs, err = scan.Packages(dir, []string{"extra"}, filepath.Join(dir, "excluded"))
+	assert.NoError(t, err)
+	if assert.Len(t, pkgs, 2) {
+		assert.Equal(t, "example.com/app/tagged", pkgs[1].ImportPath)
+	}
+}
@@ -0,0 +1,127 @@
+package vet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// listedPackage is package information printed by go list -json
+type listedPackage struct {
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+	Imports    []string
+	Export     string
+	Standard   bool
+	DepOnly    bool
+	Error      *struct {
+		Err string
+	}
+}
+
+// listPackages return packages matching patterns in dir and their dependencies, in dependency order.
+// Dependencies are compiled so that their export data can be imported.
+func listPackages(dir string, patterns ...string) ([]*listedPackage, error) {
+	args := append([]string{"list", "-e", "-export", "-json", "-deps"}, patterns...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	var pkgs []*listedPackage
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		p := &listedPackage{}
+		if err := dec.Decode(p); err != nil {
+			return nil, fmt.Errorf("go list: %w", err)
+		}
+		pkgs = append(pkgs, p)
+	}
+	return pkgs, nil
+}
+
+// sourceImporter return packages type-checked from source if available,
+// otherwise package is imported from export data produced by go list.
+type sourceImporter struct {
+	checked map[string]*types.Package
+	exports map[string]string
+	gc      types.Importer
+}
+
+// newImporter creates importer for listed packages
+func newImporter(fset *token.FileSet, pkgs []*listedPackage) *sourceImporter {
+	imp := &sourceImporter{
+		checked: map[string]*types.Package{},
+		exports: map[string]string{},
+	}
+	for _, p := range pkgs {
+		if p.Export != "" {
+			imp.exports[p.ImportPath] = p.Export
+		}
+	}
+	imp.gc = importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		file, ok := imp.exports[path]
+		if !ok {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(file)
+	})
+	return imp
+}
+
+// Import implements types.Importer
+func (imp *sourceImporter) Import(path string) (*types.Package, error) {
+	if p, ok := imp.checked[path]; ok {
+		return p, nil
+	}
+	return imp.gc.Import(path)
+}
+
+// checkedPackage is package parsed and type-checked from source
+type checkedPackage struct {
+	path  string
+	files []*ast.File
+	pkg   *types.Package
+	info  *types.Info
+}
+
+// check parse and type-check listed package.
+// Type errors are ignored, analysis works on whatever could be checked.
+func (imp *sourceImporter) check(fset *token.FileSet, p *listedPackage) (*checkedPackage, error) {
+	var files []*ast.File
+	for _, name := range p.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(p.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	info := &types.Info{
+		Types:     map[ast.Expr]types.TypeAndValue{},
+		Defs:      map[*ast.Ident]types.Object{},
+		Uses:      map[*ast.Ident]types.Object{},
+		Instances: map[*ast.Ident]types.Instance{},
+	}
+	conf := types.Config{
+		Importer: imp,
+		Error:    func(error) {},
+	}
+	pkg, _ := conf.Check(p.ImportPath, fset, files, info)
+	imp.checked[p.ImportPath] = pkg
+	return &checkedPackage{path: p.ImportPath, files: files, pkg: pkg, info: info}, nil
+}
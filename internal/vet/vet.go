@@ -0,0 +1,476 @@
+// Package vet reports factory registration mistakes found by static analysis of a module:
+// duplicate factory names, Info.Name not matching registered name, missing Info.Version
+// or Info.License, objects that do not implement the interface they are created as,
+// and configs referencing factories that are not registered.
+package vet
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+
+	"github.com/ipsusila/factory"
+	"github.com/ipsusila/factory/internal/scan"
+)
+
+// factoryPath is import path of factory package
+const factoryPath = "github.com/ipsusila/factory"
+
+// Diagnostic is a problem found by the analyzer
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+// String return diagnostic in file:line:column: message format
+func (d Diagnostic) String() string {
+	return d.Pos.String() + ": " + d.Message
+}
+
+// registration is a Register, RegisterTyped or RegisterContext call with constant name
+type registration struct {
+	name    string
+	pos     token.Position
+	returns []types.Type // concrete types returned by constructor
+	known   bool         // returns contains every type the constructor may return
+}
+
+// creation is a request to create object of given type from factory, e.g. CreateAs[T]
+type creation struct {
+	name string
+	pos  token.Position
+	typ  types.Type
+}
+
+// funcRef is function declaration and type information of its package
+type funcRef struct {
+	decl *ast.FuncDecl
+	info *types.Info
+}
+
+// varRef is package level variable initializer and type information of its package
+type varRef struct {
+	value ast.Expr
+	info  *types.Info
+}
+
+// analyzer collects registrations and references to factories
+type analyzer struct {
+	fset      *token.FileSet
+	funcs     map[types.Object]funcRef
+	vars      map[types.Object]varRef
+	regs      []registration
+	creations []creation
+	refs      []creation // configs referencing factory, typ is nil
+	diags     []Diagnostic
+}
+
+// Run analyzes packages matching patterns (default ./...) of module in dir
+// and config files (manifest or single config), returning diagnostics sorted by position.
+func Run(dir string, configs []string, patterns ...string) ([]Diagnostic, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	pkgs, err := listPackages(dir, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &analyzer{
+		fset:  token.NewFileSet(),
+		funcs: map[types.Object]funcRef{},
+		vars:  map[types.Object]varRef{},
+	}
+	imp := newImporter(a.fset, pkgs)
+	var checked []*checkedPackage
+	for _, p := range pkgs {
+		if p.Error != nil && !p.DepOnly {
+			return nil, fmt.Errorf("%s: %s", p.ImportPath, p.Error.Err)
+		}
+		if p.Standard {
+			continue
+		}
+		if p.DepOnly {
+			if err := a.scanDependency(p); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		cp, err := imp.check(a.fset, p)
+		if err != nil {
+			return nil, err
+		}
+		a.declarations(cp)
+		checked = append(checked, cp)
+	}
+
+	for _, cp := range checked {
+		for _, f := range cp.files {
+			a.inspect(cp.info, f)
+		}
+	}
+	for _, path := range configs {
+		if err := a.configFile(path); err != nil {
+			return nil, err
+		}
+	}
+	a.report()
+
+	sort.SliceStable(a.diags, func(i, j int) bool {
+		pi, pj := a.diags[i].Pos, a.diags[j].Pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return pi.Column < pj.Column
+	})
+	return a.diags, nil
+}
+
+// errorf add diagnostic
+func (a *analyzer) errorf(pos token.Position, format string, args ...interface{}) {
+	a.diags = append(a.diags, Diagnostic{Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+// scanDependency find registrations in dependency outside analyzed packages.
+// Only names are known since dependency is not type-checked from source.
+func (a *analyzer) scanDependency(p *listedPackage) error {
+	imports := false
+	for _, imp := range p.Imports {
+		imports = imports || imp == factoryPath
+	}
+	if !imports {
+		return nil
+	}
+	for _, name := range p.GoFiles {
+		regs, err := scan.File(a.fset, filepath.Join(p.Dir, name))
+		if err != nil {
+			return err
+		}
+		for _, r := range regs {
+			a.regs = append(a.regs, registration{name: r.Name, pos: r.Pos})
+		}
+	}
+	return nil
+}
+
+// declarations record package level functions and variables used to resolve Info and constructors
+func (a *analyzer) declarations(cp *checkedPackage) {
+	for _, f := range cp.files {
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil && d.Body != nil {
+					a.funcs[cp.info.Defs[d.Name]] = funcRef{decl: d, info: cp.info}
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					vs, ok := spec.(*ast.ValueSpec)
+					if !ok || len(vs.Values) != len(vs.Names) {
+						continue
+					}
+					for i, name := range vs.Names {
+						a.vars[cp.info.Defs[name]] = varRef{value: vs.Values[i], info: cp.info}
+					}
+				}
+			}
+		}
+	}
+}
+
+// inspect find registrations, creations and config literals in file
+func (a *analyzer) inspect(info *types.Info, f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			a.call(info, n)
+		case *ast.TypeAssertExpr:
+			// factory.MustCreate(c).(T)
+			call, ok := unparen(n.X).(*ast.CallExpr)
+			if !ok || n.Type == nil || len(call.Args) == 0 {
+				break
+			}
+			if fn, _ := callee(info, call.Fun); fn != nil && fn.Name() == "MustCreate" {
+				if name, ok := configName(info, call.Args[0]); ok {
+					a.creations = append(a.creations, creation{name: name, pos: a.fset.Position(n.Type.Pos()), typ: info.TypeOf(n.Type)})
+				}
+			}
+		case *ast.CompositeLit:
+			if name, ok := configName(info, n); ok {
+				a.refs = append(a.refs, creation{name: name, pos: a.fset.Position(n.Pos())})
+			}
+		}
+		return true
+	})
+}
+
+// call handle call of factory function
+func (a *analyzer) call(info *types.Info, call *ast.CallExpr) {
+	fn, id := callee(info, call.Fun)
+	if fn == nil || len(call.Args) == 0 {
+		return
+	}
+	switch fn.Name() {
+	case "Register", "RegisterTyped", "RegisterContext":
+		name, ok := constString(info, call.Args[0])
+		if !ok || len(call.Args) < 3 {
+			return
+		}
+		reg := registration{name: name, pos: a.fset.Position(call.Args[0].Pos())}
+		a.checkInfo(info, reg, call.Args[1])
+		reg.returns, reg.known = a.constructorReturns(info, call.Args[2])
+		a.regs = append(a.regs, reg)
+	case "CreateAs", "MustCreateAs":
+		inst, ok := info.Instances[id]
+		if !ok || inst.TypeArgs.Len() == 0 {
+			return
+		}
+		if name, ok := configName(info, call.Args[0]); ok {
+			a.creations = append(a.creations, creation{name: name, pos: a.fset.Position(call.Pos()), typ: inst.TypeArgs.At(0)})
+		}
+	}
+}
+
+// checkInfo report Info.Name mismatch and missing Version or License
+func (a *analyzer) checkInfo(info *types.Info, reg registration, expr ast.Expr) {
+	lit, info := a.compositeLit(info, expr)
+	if lit == nil {
+		return
+	}
+	fields := map[string]ast.Expr{}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			// positional fields, cannot tell which is which
+			return
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok {
+			fields[key.Name] = kv.Value
+		}
+	}
+
+	pos := a.fset.Position(lit.Pos())
+	if v, ok := fields["Name"]; !ok {
+		a.errorf(pos, "Info.Name is empty, factory is registered as %q", reg.name)
+	} else if name, ok := constString(info, v); ok && name != reg.name {
+		a.errorf(a.fset.Position(v.Pos()), "Info.Name %q does not match registered name %q", name, reg.name)
+	}
+	for _, field := range []string{"Version", "License"} {
+		v, ok := fields[field]
+		if !ok {
+			a.errorf(pos, "Info.%s of factory %q is empty", field, reg.name)
+		} else if s, ok := constString(info, v); ok && s == "" {
+			a.errorf(a.fset.Position(v.Pos()), "Info.%s of factory %q is empty", field, reg.name)
+		}
+	}
+}
+
+// compositeLit return composite literal of expression, following package level variable
+func (a *analyzer) compositeLit(info *types.Info, expr ast.Expr) (*ast.CompositeLit, *types.Info) {
+	switch e := unparen(expr).(type) {
+	case *ast.CompositeLit:
+		return e, info
+	case *ast.Ident, *ast.SelectorExpr:
+		if ref, ok := a.vars[objectOf(info, e)]; ok {
+			return a.compositeLit(ref.info, ref.value)
+		}
+	}
+	return nil, nil
+}
+
+// constructorReturns return concrete types of first result in return statements of constructor.
+// known is false if constructor cannot be resolved or returns interface value.
+func (a *analyzer) constructorReturns(info *types.Info, expr ast.Expr) ([]types.Type, bool) {
+	var body *ast.BlockStmt
+	switch e := unparen(expr).(type) {
+	case *ast.FuncLit:
+		body = e.Body
+	case *ast.Ident, *ast.SelectorExpr:
+		ref, ok := a.funcs[objectOf(info, e)]
+		if !ok {
+			return nil, false
+		}
+		body, info = ref.decl.Body, ref.info
+	default:
+		return nil, false
+	}
+
+	var res []types.Type
+	known := true
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(n.Results) != 2 {
+				known = false
+				return false
+			}
+			t := info.TypeOf(n.Results[0])
+			switch {
+			case t == nil:
+				known = false
+			case types.Identical(t, types.Typ[types.UntypedNil]):
+			case types.IsInterface(t):
+				known = false
+			default:
+				res = append(res, t)
+			}
+		}
+		return true
+	})
+	return res, known && len(res) > 0
+}
+
+// configFile check factory names used by configs in manifest or single config file
+func (a *analyzer) configFile(path string) error {
+	var configs []factory.Config
+	if m, err := factory.LoadManifest(path); err == nil && len(m.Instances) > 0 {
+		configs = m.Instances
+	} else {
+		c, err := factory.LoadConfig(path)
+		if err != nil {
+			return err
+		}
+		configs = []factory.Config{c}
+	}
+	for _, c := range configs {
+		p := c.Pos()
+		a.refs = append(a.refs, creation{
+			name: c.Name,
+			pos:  token.Position{Filename: p.File, Line: p.Line, Column: p.Column},
+		})
+	}
+	return nil
+}
+
+// report duplicate registrations, type mismatches and unknown factories
+func (a *analyzer) report() {
+	byName := map[string][]registration{}
+	for _, reg := range a.regs {
+		if prev := byName[reg.name]; len(prev) > 0 {
+			a.errorf(reg.pos, "factory %q is already registered at %s", reg.name, prev[0].pos)
+		}
+		byName[reg.name] = append(byName[reg.name], reg)
+	}
+
+	for _, ref := range a.refs {
+		if _, ok := byName[ref.name]; !ok {
+			a.errorf(ref.pos, "factory %q is not registered by any imported package", ref.name)
+		}
+	}
+
+	for _, c := range a.creations {
+		for _, reg := range byName[c.name] {
+			if !reg.known {
+				continue
+			}
+			for _, t := range reg.returns {
+				if !assignable(t, c.typ) {
+					a.errorf(c.pos, "factory %q creates %s which does not implement %s",
+						c.name, typeString(t), typeString(c.typ))
+				}
+			}
+		}
+	}
+}
+
+// callee return factory package function called by fun and its identifier
+func callee(info *types.Info, fun ast.Expr) (*types.Func, *ast.Ident) {
+	var id *ast.Ident
+	switch f := unparen(fun).(type) {
+	case *ast.IndexExpr:
+		return callee(info, f.X)
+	case *ast.IndexListExpr:
+		return callee(info, f.X)
+	case *ast.SelectorExpr:
+		id = f.Sel
+	case *ast.Ident:
+		id = f
+	default:
+		return nil, nil
+	}
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != factoryPath {
+		return nil, nil
+	}
+	return fn, id
+}
+
+// objectOf return object referred by identifier or qualified identifier
+func objectOf(info *types.Info, expr ast.Expr) types.Object {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return info.Uses[e]
+	case *ast.SelectorExpr:
+		return info.Uses[e.Sel]
+	}
+	return nil
+}
+
+// configName return constant Name of factory.Config composite literal
+func configName(info *types.Info, expr ast.Expr) (string, bool) {
+	lit, ok := unparen(expr).(*ast.CompositeLit)
+	if !ok || !isConfig(info.TypeOf(lit)) {
+		return "", false
+	}
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Name" {
+				return constString(info, kv.Value)
+			}
+		}
+	}
+	return "", false
+}
+
+// isConfig return true if t is factory.Config
+func isConfig(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == factoryPath && obj.Name() == "Config"
+}
+
+// constString return value of constant string expression
+func constString(info *types.Info, expr ast.Expr) (string, bool) {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// assignable return true if object of type t can be used as target type
+func assignable(t, target types.Type) bool {
+	if iface, ok := target.Underlying().(*types.Interface); ok {
+		return types.Implements(t, iface)
+	}
+	return types.Identical(t, target)
+}
+
+// typeString return type qualified by package name
+func typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		return p.Name()
+	})
+}
+
+// unparen return expression without enclosing parentheses
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		p, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = p.X
+	}
+}
@@ -0,0 +1,114 @@
+package vet_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ipsusila/factory/internal/vet"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeModule creates module using factory package from this repository
+func writeModule(t *testing.T, files map[string]string) string {
+	root, err := filepath.Abs("../..")
+	assert.NoError(t, err)
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/app\n\ngo 1.18\n\n" +
+		"require github.com/ipsusila/factory v0.0.0\n\n" +
+		"replace github.com/ipsusila/factory => " + root + "\n"
+	files["go.sum"] = string(sum)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"a/a.go": `package a
+
+import "github.com/ipsusila/factory"
+
+type Printer interface {
+	Println(args ...interface{})
+}
+
+type obj struct{}
+
+func (o *obj) ID() string { return "a" }
+
+var info = factory.Info{Name: "alpha", Version: "v0.1.0", License: "MIT"}
+
+func init() {
+	factory.Register("a", info, newObj)
+}
+
+func newObj(factory.Options) (factory.Object, error) {
+	return &obj{}, nil
+}
+`,
+		"b/b.go": `package b
+
+import "github.com/ipsusila/factory"
+
+type obj struct{}
+
+func (o *obj) ID() string { return "b" }
+
+func (o *obj) Println(args ...interface{}) {}
+
+func init() {
+	factory.Register("a", factory.Info{Name: "a", Version: ""}, func(factory.Options) (factory.Object, error) {
+		return &obj{}, nil
+	})
+	factory.RegisterTyped("b", factory.Info{Name: "b", Version: "v1", License: "MIT"}, func(factory.Options) (*obj, error) {
+		return nil, nil
+	})
+}
+`,
+		"main.go": `package main
+
+import (
+	"github.com/ipsusila/factory"
+
+	"example.com/app/a"
+	_ "example.com/app/b"
+)
+
+func main() {
+	p, _ := factory.CreateAs[a.Printer](factory.Config{Name: "a"})
+	p.Println()
+	_ = factory.MustCreate(factory.Config{Name: "nope"}).(a.Printer)
+	_ = factory.MustCreate(factory.Config{Name: "b"}).(a.Printer)
+}
+`,
+		"app.json": `{"instances": [
+  {"name": "a"},
+  {"name": "missing"}
+]}`,
+	})
+
+	diags, err := vet.Run(dir, []string{filepath.Join(dir, "app.json")})
+	assert.NoError(t, err)
+
+	var got []string
+	for _, d := range diags {
+		got = append(got, strings.TrimPrefix(d.String(), dir+string(filepath.Separator)))
+	}
+	assert.Equal(t, []string{
+		`a/a.go:13:31: Info.Name "alpha" does not match registered name "a"`,
+		`app.json:3:3: factory "missing" is not registered by any imported package`,
+		`b/b.go:12:19: factory "a" is already registered at ` + filepath.Join(dir, "a/a.go") + `:16:19`,
+		`b/b.go:12:24: Info.License of factory "a" is empty`,
+		`b/b.go:12:57: Info.Version of factory "a" is empty`,
+		`main.go:11:10: factory "a" creates *a.obj which does not implement a.Printer`,
+		`main.go:13:25: factory "nope" is not registered by any imported package`,
+	}, got)
+}
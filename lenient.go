@@ -0,0 +1,125 @@
+package factory
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	lenientMu sync.RWMutex
+	lenient   bool
+)
+
+// SetLenientParsing enables or disables lenient parsing of string values.
+// When enabled, numbers may have surrounding whitespace, hex/octal/binary prefix
+// (0x1F, 0o17, 0b101), digit separators (1_000) and exponent form for integers (1e3),
+// and booleans accept yes/no, y/n, on/off and enabled/disabled.
+// It is disabled by default.
+func SetLenientParsing(enabled bool) {
+	lenientMu.Lock()
+	defer lenientMu.Unlock()
+	lenient = enabled
+}
+
+// isLenient return true if lenient parsing is enabled
+func isLenient() bool {
+	lenientMu.RLock()
+	defer lenientMu.RUnlock()
+	return lenient
+}
+
+// errNotIntegral reported when exponent form does not represent integer
+var errNotIntegral = errors.New("not an integer")
+
+// intBase return base and normalized string of integer literal.
+// Prefixed literal is parsed with base 0, otherwise separators are removed
+// so that leading zero is not treated as octal.
+func intBase(s string) (string, int) {
+	body := strings.TrimLeft(s, "+-")
+	if len(body) > 1 && body[0] == '0' && strings.ContainsRune("xXoObB", rune(body[1])) {
+		return s, 0
+	}
+	return strings.ReplaceAll(s, "_", ""), 10
+}
+
+// parseInt parse integer string according to parsing mode
+func parseInt(s string) (int64, error) {
+	if !isLenient() {
+		return strconv.ParseInt(s, 10, 64)
+	}
+
+	str, base := intBase(strings.TrimSpace(s))
+	i, err := strconv.ParseInt(str, base, 64)
+	if err == nil || base == 0 {
+		return i, err
+	}
+	f, ferr := strconv.ParseFloat(str, 64)
+	if ferr != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) {
+		return 0, errNotIntegral
+	}
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, ErrOutOfRange
+	}
+	return int64(f), nil
+}
+
+// parseUint parse unsigned integer string according to parsing mode
+func parseUint(s string) (uint64, error) {
+	if !isLenient() {
+		return strconv.ParseUint(s, 10, 64)
+	}
+
+	str, base := intBase(strings.TrimSpace(s))
+	u, err := strconv.ParseUint(str, base, 64)
+	if err == nil || base == 0 {
+		return u, err
+	}
+	f, ferr := strconv.ParseFloat(str, 64)
+	if ferr != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) {
+		return 0, errNotIntegral
+	}
+	if f < 0 || f >= math.MaxUint64 {
+		return 0, ErrOutOfRange
+	}
+	return uint64(f), nil
+}
+
+// parseFloat parse float string according to parsing mode
+func parseFloat(s string) (float64, error) {
+	if !isLenient() {
+		return strconv.ParseFloat(s, 64)
+	}
+	str := strings.TrimSpace(s)
+	if f, err := strconv.ParseFloat(str, 64); err == nil {
+		return f, nil
+	}
+	// integer literal with prefix, e.g. 0x1F
+	i, err := parseInt(str)
+	if err != nil {
+		return strconv.ParseFloat(str, 64)
+	}
+	return float64(i), nil
+}
+
+// parseBool parse boolean string according to parsing mode
+func parseBool(s string) (bool, error) {
+	if !isLenient() {
+		return strconv.ParseBool(s)
+	}
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "t", "true", "y", "yes", "on", "enable", "enabled":
+		return true, nil
+	case "0", "f", "false", "n", "no", "off", "disable", "disabled":
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
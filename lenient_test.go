@@ -0,0 +1,51 @@
+package factory_test
+
+import (
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLenientParsing(t *testing.T) {
+	op := factory.Options{
+		"hex":  "0x1F",
+		"oct":  "0o17",
+		"bin":  "0b101",
+		"sep":  "1_000",
+		"exp":  "1e3",
+		"frac": "1.5e0",
+		"ws":   " 42 ",
+		"zero": "010",
+		"yes":  "yes",
+		"off":  "Off",
+		"is":   []interface{}{"0x10", " 2 ", "3e2"},
+		"bs":   []interface{}{"on", "disabled", 1},
+	}
+
+	// strict mode is default
+	assert.Equal(t, int64(-1), op.Int("hex", -1))
+	assert.Equal(t, int64(-1), op.Int("ws", -1))
+	assert.False(t, op.Bool("yes"))
+
+	factory.SetLenientParsing(true)
+	defer factory.SetLenientParsing(false)
+
+	assert.Equal(t, int64(31), op.Int("hex"))
+	assert.Equal(t, int64(15), op.Int("oct"))
+	assert.Equal(t, uint64(5), op.Uint("bin"))
+	assert.Equal(t, int64(1000), op.Int("sep"))
+	assert.Equal(t, int64(1000), op.Int("exp"))
+	assert.Equal(t, int64(-1), op.Int("frac", -1))
+	assert.Equal(t, int64(42), op.Int("ws"))
+	assert.Equal(t, int64(10), op.Int("zero"), "Leading zero is not octal")
+	assert.Equal(t, float64(31), op.Float("hex"))
+	assert.True(t, op.Bool("yes"))
+	assert.False(t, op.Bool("off", true))
+	assert.Equal(t, []int64{16, 2, 300}, op.IntSlice("is"))
+	assert.Equal(t, []bool{true, false, true}, op.BoolSlice("bs"))
+
+	v, err := factory.Value[int](op, "frac")
+	assert.Equal(t, 0, v)
+	assert.Equal(t, `options.frac: invalid int "1.5e0": not an integer`, err.Error())
+}
@@ -0,0 +1,191 @@
+package factory
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Include directive of manifest.
+// In JSON it is either a path string or {"path": "...", "optional": true}.
+// Path is relative to the including file and may contain glob pattern.
+type Include struct {
+	Path     string `json:"path"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// UnmarshalJSON accepts path string or include object
+func (inc *Include) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*inc = Include{Path: path}
+		return nil
+	}
+	type include Include
+	return json.Unmarshal(data, (*include)(inc))
+}
+
+// LoadManifest reads manifest from JSON file.
+// Included files are loaded first and the including file is layered on top of them
+// using Manifest.Merge, so the same rules as option layering apply.
+// Error message contains file name and key path where the problem originated.
+// If trust store is set (see SetTrustStore), signature of the resulting manifest is verified.
+func LoadManifest(path string) (*Manifest, error) {
+	l := manifestLoader{}
+	m, err := l.load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyFile(path, m.canonical); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// manifestLoader keeps track of files being loaded to detect include cycle
+type manifestLoader struct {
+	stack []string
+}
+
+// load read manifest file and its includes
+func (l *manifestLoader) load(path string) (Manifest, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Manifest{}, err
+	}
+	for i, p := range l.stack {
+		if p == abs {
+			chain := append(append([]string{}, l.stack[i:]...), abs)
+			return Manifest{}, fmt.Errorf("include cycle: %s", strings.Join(chain, " -> "))
+		}
+	}
+	l.stack = append(l.stack, abs)
+	defer func() {
+		l.stack = l.stack[:len(l.stack)-1]
+	}()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Manifest{}, err
+	}
+	doc := Manifest{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return Manifest{}, jsonError(path, data, err)
+	}
+	doc.attachSources(valuePositions(path, data))
+
+	res := Manifest{}
+	dir := filepath.Dir(path)
+	for i, inc := range doc.Include {
+		files, err := includeFiles(dir, inc)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("%s: include[%d]: %w", path, i, err)
+		}
+		for _, file := range files {
+			sub, err := l.load(file)
+			if err != nil {
+				return Manifest{}, fmt.Errorf("%s: include[%d]: %w", path, i, err)
+			}
+			res = res.Merge(sub)
+		}
+	}
+
+	doc.Include = nil
+	return res.Merge(doc), nil
+}
+
+// attachSources assign value positions to instances and profile overrides
+func (m *Manifest) attachSources(positions map[string]Position) {
+	for i := range m.Instances {
+		m.Instances[i].src = sourceAt(positions, fmt.Sprintf("instances[%d]", i))
+	}
+	m.profileSrc = map[string]map[string]*source{}
+	for name, prof := range m.Profiles {
+		srcs := map[string]*source{}
+		for key := range prof {
+			srcs[key] = overridesAt(positions, "profiles."+name+"."+key)
+		}
+		m.profileSrc[name] = srcs
+	}
+}
+
+// LoadConfig reads single config from JSON file.
+// Position of config and option values is kept so that errors returned by
+// Validate and Create point to the offending value.
+// If trust store is set (see SetTrustStore), signature of the config is verified.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	c := Config{}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Config{}, jsonError(path, data, err)
+	}
+	c.src = sourceAt(valuePositions(path, data), "")
+	if err := verifyFile(path, c.Canonical); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+// includeFiles return files matched by include directive, sorted by name
+func includeFiles(dir string, inc Include) ([]string, error) {
+	if inc.Path == "" {
+		return nil, errors.New("empty include path")
+	}
+	pattern := inc.Path
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+
+	if !strings.ContainsAny(inc.Path, "*?[") {
+		if _, err := os.Stat(pattern); err != nil {
+			if inc.Optional && errors.Is(err, os.ErrNotExist) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return []string{pattern}, nil
+	}
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inc.Path, err)
+	}
+	if len(files) == 0 && !inc.Optional {
+		return nil, fmt.Errorf("no file matches %s", inc.Path)
+	}
+	return files, nil
+}
+
+// jsonError add file name, position and key path to JSON decoding error
+func jsonError(path string, data []byte, err error) error {
+	var se *json.SyntaxError
+	if errors.As(err, &se) {
+		line, col := position(data, se.Offset)
+		return fmt.Errorf("%s:%d:%d: %w", path, line, col, err)
+	}
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) {
+		line, col := position(data, te.Offset)
+		return fmt.Errorf("%s:%d:%d: %s: cannot use %s as %s",
+			path, line, col, te.Field, te.Value, te.Type)
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// position convert byte offset into 1-based line and column
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
@@ -0,0 +1,74 @@
+package factory_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFiles create files with given content inside dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.json": `{
+			"include": ["base.json", "services/*.json", {"path": "local.json", "optional": true}],
+			"instances": [{"id": "log", "options": {"level": "debug"}}]
+		}`,
+		"base.json": `{
+			"instances": [{"id": "log", "name": "file", "options": {"filename": "app.log", "level": "info"}}],
+			"profiles": {"prod": {"log": {"level": "error"}}}
+		}`,
+		"services/a.json": `{"instances": [{"id": "a", "name": "file"}]}`,
+		"services/b.json": `{"instances": [{"id": "b", "name": "file"}], "profiles": {"prod": {"b": {"x": 1}}}}`,
+	})
+
+	m, err := factory.LoadManifest(filepath.Join(dir, "main.json"))
+	assert.Nil(t, err)
+	assert.Len(t, m.Instances, 3)
+	assert.Equal(t, "file", m.Instances[0].Name)
+	assert.Equal(t, "debug", m.Instances[0].Options.String("level"))
+	assert.Equal(t, "app.log", m.Instances[0].Options.String("filename"))
+	assert.Equal(t, "a", m.Instances[1].ID)
+	assert.Len(t, m.Profiles["prod"], 2)
+
+	configs, err := m.Resolve("prod")
+	assert.Nil(t, err)
+	assert.Equal(t, "error", configs[0].Options.String("level"))
+}
+
+func TestLoadManifestErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"cycle.json":   `{"include": ["cycle2.json"]}`,
+		"cycle2.json":  `{"include": ["cycle.json"]}`,
+		"missing.json": `{"include": ["none.json"]}`,
+		"glob.json":    `{"include": ["none/*.json"]}`,
+		"parent.json":  `{"include": ["bad.json"]}`,
+		"bad.json":     "{\n  \"instances\": [\n    {\"id\": 1}\n  ]\n}",
+	})
+
+	_, err := factory.LoadManifest(filepath.Join(dir, "cycle.json"))
+	assert.Contains(t, err.Error(), "include cycle")
+
+	_, err = factory.LoadManifest(filepath.Join(dir, "missing.json"))
+	assert.Contains(t, err.Error(), "missing.json: include[0]")
+
+	_, err = factory.LoadManifest(filepath.Join(dir, "glob.json"))
+	assert.Contains(t, err.Error(), "no file matches none/*.json")
+
+	_, err = factory.LoadManifest(filepath.Join(dir, "parent.json"))
+	assert.Contains(t, err.Error(), "parent.json: include[0]: ")
+	assert.Contains(t, err.Error(), "bad.json:3:")
+	assert.Contains(t, err.Error(), "cannot use number as string")
+}
@@ -0,0 +1,230 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Manifest describes set of instances to be created, optionally
+// adjusted by profiles (e.g. dev, staging, prod).
+type Manifest struct {
+	Include   []Include          `json:"include,omitempty" toml:"include,omitempty" yaml:"include,omitempty"`
+	Instances []Config           `json:"instances" toml:"instances" yaml:"instances"`
+	Profiles  map[string]Profile `json:"profiles,omitempty" toml:"profiles,omitempty" yaml:"profiles,omitempty"`
+
+	// position of profile overrides, keyed by profile and instance key
+	profileSrc map[string]map[string]*source
+}
+
+// Profile holds option overrides keyed by instance key (ID or factory name).
+// Overrides are merged onto instance options when the profile is active.
+type Profile map[string]Options
+
+// Condition decides whether an instance is created.
+// All specified criteria must be satisfied.
+type Condition struct {
+	// Env requires environment variables to have given values
+	Env map[string]string `json:"env,omitempty" toml:"env,omitempty" yaml:"env,omitempty"`
+
+	// Profiles requires at least one of the profiles to be active.
+	// Profile prefixed with ! requires the profile to be inactive.
+	Profiles []string `json:"profiles,omitempty" toml:"profiles,omitempty" yaml:"profiles,omitempty"`
+
+	// Factories requires all factories to be registered.
+	// Name prefixed with ! requires the factory not to be registered.
+	Factories []string `json:"factories,omitempty" toml:"factories,omitempty" yaml:"factories,omitempty"`
+}
+
+// Match return true if condition is satisfied for given active profiles.
+// Nil condition always match.
+func (c *Condition) Match(profiles ...string) bool {
+	if c == nil {
+		return true
+	}
+	for name, want := range c.Env {
+		if val, ok := os.LookupEnv(name); !ok || val != want {
+			return false
+		}
+	}
+
+	active := make(map[string]bool, len(profiles))
+	for _, p := range profiles {
+		active[p] = true
+	}
+	anyProfile, hasPositive := false, false
+	for _, p := range c.Profiles {
+		if name := strings.TrimPrefix(p, "!"); name != p {
+			if active[name] {
+				return false
+			}
+			continue
+		}
+		hasPositive = true
+		anyProfile = anyProfile || active[p]
+	}
+	if hasPositive && !anyProfile {
+		return false
+	}
+
+	for _, name := range c.Factories {
+		want := !strings.HasPrefix(name, "!")
+		if (Get(strings.TrimPrefix(name, "!")) != nil) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// Resolve return configurations of instances enabled for given active profiles.
+// Overrides of active profiles are merged onto instance options in the given order.
+func (m *Manifest) Resolve(profiles ...string) ([]Config, error) {
+	seen := map[string]bool{}
+	for i, c := range m.Instances {
+		key := c.Key()
+		if seen[key] {
+			return nil, fmt.Errorf("instances[%d]: duplicate instance %s", i, key)
+		}
+		seen[key] = true
+	}
+	for _, p := range profiles {
+		prof, ok := m.Profiles[p]
+		if !ok {
+			return nil, fmt.Errorf("profile %s is not defined", p)
+		}
+		for key := range prof {
+			if !seen[key] {
+				return nil, fmt.Errorf("profiles.%s: unknown instance %s", p, key)
+			}
+		}
+	}
+
+	res := make([]Config, 0, len(m.Instances))
+	for _, c := range m.Instances {
+		key := c.Key()
+		if !c.When.Match(profiles...) {
+			continue
+		}
+
+		opts := c.Options
+		for _, p := range profiles {
+			if ov, ok := m.Profiles[p][key]; ok {
+				opts = opts.Merge(ov)
+				c.src = c.src.overlay(m.profileSrc[p][key])
+			}
+		}
+		c.Options = opts
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+// Merge return new manifest where other is layered on top of m.
+// Instance with the same key has its options merged (see Options.Merge)
+// and its name and condition replaced if specified. Profile overrides are merged per instance.
+// Include directives are not merged.
+func (m Manifest) Merge(other Manifest) Manifest {
+	res := Manifest{
+		Instances: make([]Config, 0, len(m.Instances)+len(other.Instances)),
+		Profiles:  make(map[string]Profile, len(m.Profiles)+len(other.Profiles)),
+	}
+
+	index := map[string]int{}
+	for _, c := range m.Instances {
+		index[c.Key()] = len(res.Instances)
+		res.Instances = append(res.Instances, c)
+	}
+	for _, c := range other.Instances {
+		i, ok := index[c.Key()]
+		if !ok {
+			index[c.Key()] = len(res.Instances)
+			res.Instances = append(res.Instances, c)
+			continue
+		}
+		base := res.Instances[i]
+		if c.Name != "" {
+			base.Name = c.Name
+		}
+		if c.When != nil {
+			base.When = c.When
+		}
+		base.Options = base.Options.Merge(c.Options)
+		base.src = base.src.overlay(c.src)
+		res.Instances[i] = base
+	}
+
+	res.profileSrc = map[string]map[string]*source{}
+	for _, layer := range []Manifest{m, other} {
+		for name, prof := range layer.Profiles {
+			dst, ok := res.Profiles[name]
+			if !ok {
+				dst = Profile{}
+				res.Profiles[name] = dst
+				res.profileSrc[name] = map[string]*source{}
+			}
+			for key, ov := range prof {
+				dst[key] = dst[key].Merge(ov)
+				src := res.profileSrc[name]
+				src[key] = src[key].overlay(layer.profileSrc[name][key])
+			}
+		}
+	}
+	return res
+}
+
+// Create creates all instances enabled for given active profiles.
+// If one of the instances failed, instances already created are closed.
+func (m *Manifest) Create(profiles ...string) ([]Object, error) {
+	return m.CreateContext(context.Background(), profiles...)
+}
+
+// CreateContext is like Create, every instance is created using CreateContext with given context.
+func (m *Manifest) CreateContext(ctx context.Context, profiles ...string) ([]Object, error) {
+	configs, err := m.Resolve(profiles...)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := make([]Object, 0, len(configs))
+	for _, c := range configs {
+		obj, err := CreateContext(ctx, c)
+		if err != nil {
+			closeAll(objs)
+			return nil, instanceError(c, err)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// Validate resolves instances for given active profiles and validates them without creating objects.
+func (m *Manifest) Validate(profiles ...string) error {
+	configs, err := m.Resolve(profiles...)
+	if err != nil {
+		return err
+	}
+	for _, c := range configs {
+		if err := Validate(c); err != nil {
+			return instanceError(c, err)
+		}
+	}
+	return nil
+}
+
+// instanceError add instance key to error that does not have file position
+func instanceError(c Config, err error) error {
+	var se *SourceError
+	if errors.As(err, &se) {
+		return err
+	}
+	return fmt.Errorf("instance %s: %w", c.Key(), err)
+}
+
+// closeAll close objects in reverse order, see Close
+func closeAll(objs []Object) {
+	for i := len(objs) - 1; i >= 0; i-- {
+		Close(objs[i])
+	}
+}
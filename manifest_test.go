@@ -0,0 +1,86 @@
+package factory_test
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/ipsusila/factory/impl/file"
+)
+
+const manifestData = `{
+	"instances": [
+		{"id": "license", "name": "file", "options": {"filename": "README.md", "buffer": {"size": 512, "mode": "r"}}},
+		{"id": "debug", "name": "file", "options": {"filename": "LICENSE"}, "when": {"profiles": ["dev"]}},
+		{"id": "secure", "name": "file", "options": {"filename": "LICENSE"}, "when": {"env": {"FACTORY_TEST_SECURE": "1"}}},
+		{"id": "missing", "name": "no-such-factory", "when": {"factories": ["no-such-factory"]}}
+	],
+	"profiles": {
+		"dev": {},
+		"prod": {"license": {"filename": "LICENSE", "buffer": {"size": 4096}}}
+	}
+}`
+
+func TestOptionsMerge(t *testing.T) {
+	base := factory.Options{
+		"a": 1,
+		"b": map[string]interface{}{"x": 1, "y": 2},
+		"c": "keep",
+	}
+	res := base.Merge(factory.Options{
+		"a": 2,
+		"b": factory.Options{"y": 3, "z": 4},
+		"c": nil,
+	})
+	assert.Equal(t, 2, res["a"])
+	assert.Equal(t, factory.Options{"x": 1, "y": 3, "z": 4}, res["b"])
+	assert.False(t, res.Has("c"))
+	assert.Equal(t, 1, base["a"], "Base shall not be modified")
+}
+
+func TestManifestProfiles(t *testing.T) {
+	m := factory.Manifest{}
+	assert.Nil(t, json.Unmarshal([]byte(manifestData), &m))
+
+	configs, err := m.Resolve()
+	assert.Nil(t, err)
+	assert.Len(t, configs, 1)
+	assert.Equal(t, "README.md", configs[0].Options.String("filename"))
+
+	configs, err = m.Resolve("prod")
+	assert.Nil(t, err)
+	assert.Len(t, configs, 1)
+	assert.Equal(t, "LICENSE", configs[0].Options.String("filename"))
+	buf, _ := factory.Value[factory.Options](configs[0].Options, "buffer")
+	assert.Equal(t, int64(4096), buf.Int("size"))
+	assert.Equal(t, "r", buf.String("mode"))
+
+	configs, err = m.Resolve("dev")
+	assert.Nil(t, err)
+	assert.Len(t, configs, 2)
+
+	t.Setenv("FACTORY_TEST_SECURE", "1")
+	configs, err = m.Resolve()
+	assert.Nil(t, err)
+	assert.Len(t, configs, 2)
+	assert.Equal(t, "secure", configs[1].ID)
+
+	_, err = m.Resolve("staging")
+	assert.NotNil(t, err)
+
+	configs, err = m.Resolve()
+	assert.Nil(t, err)
+	buf, err = factory.Value[factory.Options](configs[0].Options, "buffer")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(512), buf.Int("size"))
+
+	objs, err := m.Create("prod")
+	assert.Nil(t, err)
+	assert.Len(t, objs, 2)
+	for _, obj := range objs {
+		obj.(io.Closer).Close()
+	}
+}
@@ -0,0 +1,25 @@
+package factory
+
+// Merge return new options where overrides are layered on top of o.
+// Nested options are merged recursively, other values are replaced,
+// and nil value in overrides removes the key. Neither o nor overrides is modified.
+func (o Options) Merge(overrides Options) Options {
+	res := make(Options, len(o)+len(overrides))
+	for key, val := range o {
+		res[key] = val
+	}
+	for key, val := range overrides {
+		if val == nil {
+			delete(res, key)
+			continue
+		}
+		if ov, ok := toOptions(val); ok {
+			if base, ok := toOptions(res[key]); ok {
+				res[key] = base.Merge(ov)
+				continue
+			}
+		}
+		res[key] = val
+	}
+	return res
+}
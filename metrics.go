@@ -0,0 +1,183 @@
+package factory
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Error classes of failed Create calls, see ErrorClass
+const (
+	ClassMissingOption = "missing_option"
+	ClassInvalidOption = "invalid_option"
+	ClassDecrypt       = "decrypt"
+	ClassConstructor   = "constructor"
+)
+
+// LatencyBuckets are upper bounds, in seconds, of Create latency histogram buckets
+var LatencyBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}
+
+// MetricsSink receives measurements of Create calls.
+// Methods may be called concurrently and must not block.
+type MetricsSink interface {
+	// ObserveCreate is called when Create of factory returned.
+	// Class is empty if object was created, otherwise it is the error class (see ErrorClass).
+	ObserveCreate(factory string, elapsed time.Duration, class string)
+
+	// AddLive is called when number of tracked instances (see TrackInstances) of factory changed by delta.
+	AddLive(factory string, delta int)
+}
+
+// Histogram of Create latency
+type Histogram struct {
+	Buckets []float64 `json:"buckets"` // upper bounds in seconds
+	Counts  []uint64  `json:"counts"`  // cumulative count of each bucket
+	Count   uint64    `json:"count"`
+	Sum     float64   `json:"sum"` // total seconds
+}
+
+// CreateStats holds measurements of Create calls of a factory
+type CreateStats struct {
+	Factory string            `json:"factory"`
+	Created uint64            `json:"created"`
+	Failed  map[string]uint64 `json:"failed,omitempty"` // by error class
+	Live    int               `json:"live"`             // number of tracked instances
+	Latency Histogram         `json:"latency"`
+}
+
+var (
+	sinksMu sync.RWMutex
+	sinks   []MetricsSink
+	stats   = &statsSink{byName: map[string]*CreateStats{}}
+)
+
+// AddMetricsSink adds sink that receives measurements of every Create call.
+// Measurements are always collected by the registry and available through Metrics,
+// sink is meant for forwarding them to other monitoring backends.
+func AddMetricsSink(s MetricsSink) {
+	if s == nil {
+		panic("factory: AddMetricsSink sink is nil")
+	}
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks = append(sinks, s)
+}
+
+// Metrics return snapshot of Create measurements of factories that have been used, sorted by factory name
+func Metrics() []CreateStats {
+	return stats.snapshot()
+}
+
+// ErrorClass return class of error returned by Create:
+// missing_option, invalid_option, decrypt or constructor. Nil error has empty class.
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, ErrMissingOption) {
+		return ClassMissingOption
+	}
+	var ve *ValueError
+	if errors.As(err, &ve) {
+		if ve.Type == encryptedType {
+			return ClassDecrypt
+		}
+		return ClassInvalidOption
+	}
+	return ClassConstructor
+}
+
+// observeCreate forward measurement of Create call to the registry and sinks
+func observeCreate(factory string, elapsed time.Duration, err error) {
+	class := ErrorClass(err)
+	stats.ObserveCreate(factory, elapsed, class)
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	for _, s := range sinks {
+		s.ObserveCreate(factory, elapsed, class)
+	}
+}
+
+// addLive forward change of tracked instances count to the registry and sinks
+func addLive(factory string, delta int) {
+	stats.AddLive(factory, delta)
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	for _, s := range sinks {
+		s.AddLive(factory, delta)
+	}
+}
+
+// statsSink collects measurements in memory
+type statsSink struct {
+	mu     sync.Mutex
+	byName map[string]*CreateStats
+}
+
+// get return stats of factory, creating it if needed. Caller must hold mu.
+func (s *statsSink) get(factory string) *CreateStats {
+	st, ok := s.byName[factory]
+	if !ok {
+		st = &CreateStats{
+			Factory: factory,
+			Failed:  map[string]uint64{},
+			Latency: Histogram{
+				Buckets: LatencyBuckets,
+				Counts:  make([]uint64, len(LatencyBuckets)),
+			},
+		}
+		s.byName[factory] = st
+	}
+	return st
+}
+
+// ObserveCreate implements MetricsSink
+func (s *statsSink) ObserveCreate(factory string, elapsed time.Duration, class string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.get(factory)
+	if class == "" {
+		st.Created++
+	} else {
+		st.Failed[class]++
+	}
+
+	h := &st.Latency
+	sec := elapsed.Seconds()
+	h.Count++
+	h.Sum += sec
+	for i, le := range h.Buckets {
+		if sec <= le {
+			h.Counts[i]++
+		}
+	}
+}
+
+// AddLive implements MetricsSink
+func (s *statsSink) AddLive(factory string, delta int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.get(factory).Live += delta
+}
+
+// snapshot return copy of collected stats
+func (s *statsSink) snapshot() []CreateStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]CreateStats, 0, len(s.byName))
+	for _, st := range s.byName {
+		cp := *st
+		cp.Failed = make(map[string]uint64, len(st.Failed))
+		for class, n := range st.Failed {
+			cp.Failed[class] = n
+		}
+		cp.Latency.Buckets = append([]float64{}, st.Latency.Buckets...)
+		cp.Latency.Counts = append([]uint64{}, st.Latency.Counts...)
+		res = append(res, cp)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Factory < res[j].Factory
+	})
+	return res
+}
@@ -0,0 +1,108 @@
+// Package metrics exposes Create measurements collected by the factory registry
+// through expvar and in Prometheus text format.
+//
+// Typical usage:
+//
+//	metrics.Publish("factory")
+//	mux.Handle("/metrics", metrics.Handler())
+//
+// Other monitoring backends can receive measurements by implementing
+// factory.MetricsSink and passing it to factory.AddMetricsSink.
+package metrics
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ipsusila/factory"
+)
+
+// ContentType of Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Publish exports factory.Metrics as expvar variable with given name.
+// Like expvar.Publish, it panics if the name is already in use.
+func Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return factory.Metrics()
+	}))
+}
+
+// Handler return http.Handler that writes factory.Metrics in Prometheus text format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		WritePrometheus(w, factory.Metrics())
+	})
+}
+
+// WritePrometheus writes stats in Prometheus text format:
+//
+//	factory_create_total{factory}                    successful Create calls
+//	factory_create_errors_total{factory,class}       failed Create calls by error class
+//	factory_create_duration_seconds{factory}         histogram of Create latency
+//	factory_live_instances{factory}                  number of tracked instances
+func WritePrometheus(w io.Writer, stats []factory.CreateStats) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "# HELP factory_create_total Number of objects created by factory.")
+	fmt.Fprintln(bw, "# TYPE factory_create_total counter")
+	for _, st := range stats {
+		fmt.Fprintf(bw, "factory_create_total{factory=%s} %d\n", quote(st.Factory), st.Created)
+	}
+
+	fmt.Fprintln(bw, "# HELP factory_create_errors_total Number of failed Create calls by error class.")
+	fmt.Fprintln(bw, "# TYPE factory_create_errors_total counter")
+	for _, st := range stats {
+		classes := make([]string, 0, len(st.Failed))
+		for class := range st.Failed {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		for _, class := range classes {
+			fmt.Fprintf(bw, "factory_create_errors_total{factory=%s,class=%s} %d\n",
+				quote(st.Factory), quote(class), st.Failed[class])
+		}
+	}
+
+	fmt.Fprintln(bw, "# HELP factory_create_duration_seconds Latency of Create calls.")
+	fmt.Fprintln(bw, "# TYPE factory_create_duration_seconds histogram")
+	for _, st := range stats {
+		name := quote(st.Factory)
+		h := st.Latency
+		for i, le := range h.Buckets {
+			fmt.Fprintf(bw, "factory_create_duration_seconds_bucket{factory=%s,le=\"%s\"} %d\n",
+				name, formatFloat(le), h.Counts[i])
+		}
+		fmt.Fprintf(bw, "factory_create_duration_seconds_bucket{factory=%s,le=\"+Inf\"} %d\n", name, h.Count)
+		fmt.Fprintf(bw, "factory_create_duration_seconds_sum{factory=%s} %s\n", name, formatFloat(h.Sum))
+		fmt.Fprintf(bw, "factory_create_duration_seconds_count{factory=%s} %d\n", name, h.Count)
+	}
+
+	fmt.Fprintln(bw, "# HELP factory_live_instances Number of tracked instances created by factory.")
+	fmt.Fprintln(bw, "# TYPE factory_live_instances gauge")
+	for _, st := range stats {
+		fmt.Fprintf(bw, "factory_live_instances{factory=%s} %d\n", quote(st.Factory), st.Live)
+	}
+
+	return bw.Flush()
+}
+
+// labelEscaper escapes label value as required by text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quote return quoted label value
+func quote(s string) string {
+	return `"` + labelEscaper.Replace(s) + `"`
+}
+
+// formatFloat format float in the shortest representation
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,81 @@
+package metrics_test
+
+import (
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/ipsusila/factory/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+type job struct{}
+
+func (j *job) ID() string {
+	return "Job"
+}
+
+func init() {
+	info := factory.Info{
+		Name: "job",
+		Options: []factory.OptionSpec{
+			{Name: "queue", Type: "string", Required: true},
+			{Name: "workers", Type: "int"},
+		},
+	}
+	factory.Register("job", info, func(o factory.Options) (factory.Object, error) {
+		if o.String("queue") == "broken" {
+			return nil, errors.New("queue is broken")
+		}
+		return &job{}, nil
+	})
+}
+
+func TestHandler(t *testing.T) {
+	factory.MustCreate(factory.Config{Name: "job", Options: factory.Options{"queue": "mail"}})
+	for _, opts := range []factory.Options{
+		{},
+		{"queue": "mail", "workers": "many"},
+		{"queue": "broken"},
+		{"queue": "broken"},
+	} {
+		_, err := factory.Create(factory.Config{Name: "job", Options: opts})
+		assert.Error(t, err)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, metrics.ContentType, rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "# TYPE factory_create_total counter\nfactory_create_total{factory=\"job\"} 1\n")
+	assert.Contains(t, body, `factory_create_errors_total{factory="job",class="constructor"} 2`)
+	assert.Contains(t, body, `factory_create_errors_total{factory="job",class="invalid_option"} 1`)
+	assert.Contains(t, body, `factory_create_errors_total{factory="job",class="missing_option"} 1`)
+	assert.Contains(t, body, `factory_create_duration_seconds_bucket{factory="job",le="0.001"}`)
+	assert.Contains(t, body, `factory_create_duration_seconds_bucket{factory="job",le="+Inf"} 5`)
+	assert.Contains(t, body, `factory_create_duration_seconds_count{factory="job"} 5`)
+	assert.Contains(t, body, `factory_live_instances{factory="job"} 0`)
+
+	metrics.Publish("factory")
+	v := expvar.Get("factory")
+	if assert.NotNil(t, v) {
+		assert.Contains(t, v.String(), `"factory":"job","created":1`)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	stats := []factory.CreateStats{{
+		Factory: `a"b`,
+		Live:    2,
+		Latency: factory.Histogram{Buckets: []float64{0.5}, Counts: []uint64{1}, Count: 2, Sum: 1.25},
+	}}
+	rec := httptest.NewRecorder()
+	assert.NoError(t, metrics.WritePrometheus(rec, stats))
+	body := rec.Body.String()
+	assert.Contains(t, body, `factory_create_duration_seconds_bucket{factory="a\"b",le="0.5"} 1`)
+	assert.Contains(t, body, `factory_create_duration_seconds_sum{factory="a\"b"} 1.25`)
+	assert.Contains(t, body, `factory_live_instances{factory="a\"b"} 2`)
+}
@@ -0,0 +1,68 @@
+package factory_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSink struct {
+	mu      sync.Mutex
+	creates []string
+	live    map[string]int
+}
+
+func (s *recordingSink) ObserveCreate(name string, elapsed time.Duration, class string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.creates = append(s.creates, name+":"+class)
+}
+
+func (s *recordingSink) AddLive(name string, delta int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.live[name] += delta
+}
+
+func TestErrorClass(t *testing.T) {
+	assert.Equal(t, "", factory.ErrorClass(nil))
+	assert.Equal(t, factory.ClassMissingOption, factory.ErrorClass(&factory.ValueError{Key: "a", Err: factory.ErrMissingOption}))
+	assert.Equal(t, factory.ClassInvalidOption, factory.ErrorClass(fmt.Errorf("x: %w", &factory.ValueError{Type: "int"})))
+	assert.Equal(t, factory.ClassConstructor, factory.ErrorClass(errors.New("dial failed")))
+}
+
+func TestMetricsSink(t *testing.T) {
+	sink := &recordingSink{live: map[string]int{}}
+	factory.AddMetricsSink(sink)
+	factory.TrackInstances(true)
+	defer factory.TrackInstances(false)
+
+	c := factory.Config{Name: "file", Options: factory.Options{"filename": "LICENSE"}}
+	fo := factory.MustCreate(c)
+	_, err := factory.Create(factory.Config{Name: "file", Options: factory.Options{}})
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"file:", "file:missing_option"}, sink.creates)
+	assert.Equal(t, 1, sink.live["file"])
+
+	var stats factory.CreateStats
+	for _, st := range factory.Metrics() {
+		if st.Factory == "file" {
+			stats = st
+		}
+	}
+	assert.NotZero(t, stats.Created)
+	assert.NotZero(t, stats.Failed[factory.ClassMissingOption])
+	assert.Equal(t, 1, stats.Live)
+	assert.Equal(t, stats.Created+stats.Failed[factory.ClassMissingOption], stats.Latency.Count)
+
+	fo.(io.Closer).Close()
+	factory.Release(fo)
+	assert.Equal(t, 0, sink.live["file"])
+}
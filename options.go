@@ -3,28 +3,10 @@ package factory
 import (
 	"fmt"
 	"math"
-	"reflect"
 	"strconv"
 	"time"
 )
 
-// Supported time layout
-var tmLayouts = []string{
-	time.RFC3339,
-	"2006-01-02 15:04:05Z07:00",
-	"02/01/2006 15:04:05Z07:00",
-	time.RFC3339Nano,
-	time.RFC1123,
-	time.RFC1123Z,
-	time.RFC822,
-	time.RFC822Z,
-	time.RFC850,
-	time.ANSIC,
-	time.Layout,
-	time.RubyDate,
-	time.UnixDate,
-}
-
 // Options for object construction
 type Options map[string]interface{}
 
@@ -36,232 +18,277 @@ func (o Options) Has(key string) bool {
 
 // toString convert interface{} to string
 func (o Options) toString(val interface{}, defV string) string {
+	val, _ = reveal(val)
+	if s, err := o.asString(val); err == nil {
+		return s
+	}
+	return defV
+}
+
+// asString convert interface{} to string or return error
+func (o Options) asString(val interface{}) (string, error) {
 	switch v := val.(type) {
 	case string:
-		return v
+		return v, nil
 	case bool:
-		return strconv.FormatBool(v)
+		return strconv.FormatBool(v), nil
 	case int64:
-		return strconv.FormatInt(v, 10)
+		return strconv.FormatInt(v, 10), nil
 	case uint64:
-		return strconv.FormatUint(v, 10)
+		return strconv.FormatUint(v, 10), nil
 	case int:
-		return strconv.Itoa(v)
+		return strconv.Itoa(v), nil
 	case uint:
-		return strconv.FormatUint(uint64(v), 10)
+		return strconv.FormatUint(uint64(v), 10), nil
 	case int32:
-		return strconv.Itoa(int(v))
+		return strconv.Itoa(int(v)), nil
 	case uint32:
-		return strconv.FormatUint(uint64(v), 10)
+		return strconv.FormatUint(uint64(v), 10), nil
 	case int16:
-		return strconv.Itoa(int(v))
+		return strconv.Itoa(int(v)), nil
 	case uint16:
-		return strconv.Itoa(int(v))
+		return strconv.Itoa(int(v)), nil
 	case int8:
-		return strconv.Itoa(int(v))
+		return strconv.Itoa(int(v)), nil
 	case uint8:
-		return strconv.Itoa(int(v))
+		return strconv.Itoa(int(v)), nil
 	case float64:
-		return strconv.FormatFloat(v, 'f', -1, 64)
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
 	case float32:
-		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
 	case fmt.Stringer:
-		return v.String()
+		return v.String(), nil
 	}
-	return defV
+	return "", invalidValue("string", val, nil)
 }
 
 // toBool convert value to boolean or default value
 func (o Options) toBool(val interface{}, defV bool) bool {
+	val, _ = reveal(val)
+	if b, err := o.asBool(val); err == nil {
+		return b
+	}
+	return defV
+}
+
+// asBool convert value to boolean or return error
+func (o Options) asBool(val interface{}) (bool, error) {
 	switch v := val.(type) {
 	case bool:
-		return v
+		return v, nil
 	case int64:
-		return v != 0
+		return v != 0, nil
 	case uint64:
-		return v != 0
+		return v != 0, nil
 	case int:
-		return v != 0
+		return v != 0, nil
 	case uint:
-		return v != 0
+		return v != 0, nil
 	case int32:
-		return v != 0
+		return v != 0, nil
 	case uint32:
-		return v != 0
+		return v != 0, nil
 	case int16:
-		return v != 0
+		return v != 0, nil
 	case uint16:
-		return v != 0
+		return v != 0, nil
 	case int8:
-		return v != 0
+		return v != 0, nil
 	case uint8:
-		return v != 0
+		return v != 0, nil
 	case float64:
-		return v != 0
+		return v != 0, nil
 	case float32:
-		return v != 0
+		return v != 0, nil
 	case string:
-		b, err := strconv.ParseBool(v)
+		b, err := parseBool(v)
 		if err != nil {
-			return defV
+			return false, invalidValue("bool", val, err)
 		}
-		return b
+		return b, nil
 	case fmt.Stringer:
-		b, err := strconv.ParseBool(v.String())
+		b, err := parseBool(v.String())
 		if err != nil {
-			return defV
+			return false, invalidValue("bool", val, err)
 		}
-		return b
+		return b, nil
 	}
 
-	return defV
+	return false, invalidValue("bool", val, nil)
 }
 
 // convert interface val to 64-integer
 func (o Options) toInt(val interface{}, defV int64) int64 {
+	val, _ = reveal(val)
+	if i, err := o.asInt(val); err == nil {
+		return i
+	}
+	return defV
+}
+
+// asInt convert interface val to 64-integer or return error
+func (o Options) asInt(val interface{}) (int64, error) {
 	switch v := val.(type) {
 	case int64:
-		return v
+		return v, nil
 	case uint64:
 		if v > math.MaxInt64 {
-			return defV
+			return 0, outOfRange("int", val)
 		}
-		return int64(v)
+		return int64(v), nil
 	case int:
-		return int64(v)
+		return int64(v), nil
 	case uint:
-		if v > math.MaxInt64 {
-			return defV
+		if uint64(v) > math.MaxInt64 {
+			return 0, outOfRange("int", val)
 		}
-		return int64(v)
+		return int64(v), nil
 	case int32:
-		return int64(v)
+		return int64(v), nil
 	case uint32:
-		return int64(v)
+		return int64(v), nil
 	case int16:
-		return int64(v)
+		return int64(v), nil
 	case uint16:
-		return int64(v)
+		return int64(v), nil
 	case int8:
-		return int64(v)
+		return int64(v), nil
 	case uint8:
-		return int64(v)
+		return int64(v), nil
 	case float32:
 		iv := int64(v)
 		if float32(iv) == v {
-			return iv
+			return iv, nil
 		}
-		return defV
+		return 0, invalidValue("int", val, nil)
 	case float64:
 		iv := int64(v)
 		if float64(iv) == v {
-			return iv
+			return iv, nil
 		}
-		return defV
+		return 0, invalidValue("int", val, nil)
 	case bool:
 		if v {
-			return 1
+			return 1, nil
 		}
-		return 0
+		return 0, nil
 	case string:
-		res, err := strconv.ParseInt(v, 10, 64)
+		res, err := parseInt(v)
 		if err != nil {
-			return defV
+			return 0, invalidValue("int", val, err)
 		}
-		return res
+		return res, nil
 	case fmt.Stringer:
-		res, err := strconv.ParseInt(v.String(), 10, 64)
+		res, err := parseInt(v.String())
 		if err != nil {
-			return defV
+			return 0, invalidValue("int", val, err)
 		}
-		return res
+		return res, nil
 	}
 
-	return defV
+	return 0, invalidValue("int", val, nil)
 }
 
 // convert to unsigned integer
 func (o Options) toUint(val interface{}, defV uint64) uint64 {
+	val, _ = reveal(val)
+	if u, err := o.asUint(val); err == nil {
+		return u
+	}
+	return defV
+}
+
+// asUint convert to unsigned integer or return error
+func (o Options) asUint(val interface{}) (uint64, error) {
 	switch v := val.(type) {
 	case uint64:
-		return v
+		return v, nil
 	case int64:
 		if v < 0 {
-			return defV
+			return 0, outOfRange("uint", val)
 		}
-		return uint64(v)
+		return uint64(v), nil
 	case int:
 		if v < 0 {
-			return defV
+			return 0, outOfRange("uint", val)
 		}
-		return uint64(v)
+		return uint64(v), nil
 	case uint:
-		return uint64(v)
+		return uint64(v), nil
 	case int32:
 		if v < 0 {
-			return defV
+			return 0, outOfRange("uint", val)
 		}
-		return uint64(v)
+		return uint64(v), nil
 	case uint32:
-		return uint64(v)
+		return uint64(v), nil
 	case int16:
 		if v < 0 {
-			return defV
+			return 0, outOfRange("uint", val)
 		}
-		return uint64(v)
+		return uint64(v), nil
 	case uint16:
-		return uint64(v)
+		return uint64(v), nil
 	case int8:
 		if v < 0 {
-			return defV
+			return 0, outOfRange("uint", val)
 		}
-		return uint64(v)
+		return uint64(v), nil
 	case uint8:
-		return uint64(v)
+		return uint64(v), nil
 	case float32:
 		if v < 0 {
-			return defV
+			return 0, outOfRange("uint", val)
 		}
 
 		// convertible if no fraction
 		iv := uint64(v)
 		if float32(iv) == v {
-			return iv
+			return iv, nil
 		}
-		return defV
+		return 0, invalidValue("uint", val, nil)
 	case float64:
 		if v < 0 {
-			return defV
+			return 0, outOfRange("uint", val)
 		}
 
 		iv := uint64(v)
 		if float64(iv) == v {
-			return iv
+			return iv, nil
 		}
-		return defV
+		return 0, invalidValue("uint", val, nil)
 	case bool:
 		if v {
-			return 1
+			return 1, nil
 		}
-		return 0
+		return 0, nil
 	case string:
-		res, err := strconv.ParseUint(v, 10, 64)
+		res, err := parseUint(v)
 		if err != nil {
-			return defV
+			return 0, invalidValue("uint", val, err)
 		}
-		return res
+		return res, nil
 	case fmt.Stringer:
-		res, err := strconv.ParseUint(v.String(), 10, 64)
+		res, err := parseUint(v.String())
 		if err != nil {
-			return defV
+			return 0, invalidValue("uint", val, err)
 		}
-		return res
+		return res, nil
 	}
 
-	return defV
+	return 0, invalidValue("uint", val, nil)
 }
 
 func (o Options) toFloat(val interface{}, defV float64) float64 {
+	val, _ = reveal(val)
+	if f, err := o.asFloat(val); err == nil {
+		return f
+	}
+	return defV
+}
+
+// asFloat convert value to float64 or return error
+func (o Options) asFloat(val interface{}) (float64, error) {
 	// maximum integer that exactly
 	// can be represented as float
 	const maxI = int64(1) << 53
@@ -269,94 +296,70 @@ func (o Options) toFloat(val interface{}, defV float64) float64 {
 
 	switch v := val.(type) {
 	case float64:
-		return v
+		return v, nil
 	case float32:
-		return float64(v)
+		return float64(v), nil
 	case int64:
 		if v > maxI || v < minI {
-			return defV
+			return 0, outOfRange("float", val)
 		}
-		return float64(v)
+		return float64(v), nil
 	case uint64:
 		if v > uint64(maxI) {
-			return defV
+			return 0, outOfRange("float", val)
 		}
-		return float64(v)
+		return float64(v), nil
 	case int:
 		iv := int64(v)
 		if iv > maxI || iv < minI {
-			return defV
+			return 0, outOfRange("float", val)
 		}
-		return float64(v)
+		return float64(v), nil
 	case uint:
 		uv := uint64(v)
 		if uv > uint64(maxI) {
-			return defV
+			return 0, outOfRange("float", val)
 		}
-		return float64(v)
+		return float64(v), nil
 	case int32:
-		return float64(v)
+		return float64(v), nil
 	case uint32:
-		return float64(v)
+		return float64(v), nil
 	case int16:
-		return float64(v)
+		return float64(v), nil
 	case uint16:
-		return float64(v)
+		return float64(v), nil
 	case int8:
-		return float64(v)
+		return float64(v), nil
 	case uint8:
-		return float64(v)
+		return float64(v), nil
 	case bool:
 		if v {
-			return 1
+			return 1, nil
 		}
-		return 0
+		return 0, nil
 	case string:
-		fv, err := strconv.ParseFloat(v, 64)
+		fv, err := parseFloat(v)
 		if err != nil {
-			return defV
+			return 0, invalidValue("float", val, err)
 		}
-		return fv
+		return fv, nil
 	case fmt.Stringer:
-		fv, err := strconv.ParseFloat(v.String(), 64)
+		fv, err := parseFloat(v.String())
 		if err != nil {
-			return defV
+			return 0, invalidValue("float", val, err)
 		}
-		return fv
+		return fv, nil
 	}
 
-	return defV
+	return 0, invalidValue("float", val, nil)
 }
 
 // convert to duration
 func (o Options) toDuration(val interface{}, defV time.Duration) time.Duration {
-	switch v := val.(type) {
-	case time.Duration:
-		return v
-	case string:
-		d, err := time.ParseDuration(v)
-		if err != nil {
-			return defV
-		}
+	val, _ = reveal(val)
+	if d, err := o.asDuration(val); err == nil {
 		return d
-	case fmt.Stringer:
-		d, err := time.ParseDuration(v.String())
-		if err != nil {
-			return defV
-		}
-		return d
-	default:
-		iDef := int64(defV)
-		iv := o.toInt(val, iDef)
-		return time.Duration(iv)
-	}
-}
-
-func (o Options) parseTime(str string, defV time.Time) time.Time {
-	for _, layout := range tmLayouts {
-		if tm, err := time.Parse(layout, str); err == nil {
-			return tm
-		}
 	}
 	return defV
 }
@@ -364,19 +367,11 @@ func (o Options) parseTime(str string, defV time.Time) time.Time {
 // toTime convert interface value to time.Time
 // or default value if invalid/not specified.
 func (o Options) toTime(val interface{}, defV time.Time) time.Time {
-	switch v := val.(type) {
-	case time.Time:
-		return v
-	case string:
-		return o.parseTime(v, defV)
-	case fmt.Stringer:
-		return o.parseTime(v.String(), defV)
-	default:
-		// get from timestamp
-		iDef := defV.Unix()
-		iv := o.toInt(val, iDef)
-		return time.Unix(iv, 0)
+	val, _ = reveal(val)
+	if tm, err := o.asTime(val); err == nil {
+		return tm
 	}
+	return defV
 }
 
 // String return string value from options.
@@ -485,191 +480,52 @@ func (o Options) Time(key string, def ...time.Time) time.Time {
 	return o.toTime(val, defV)
 }
 
-// StringSlice returns slice of string or default value
+// StringSlice returns slice of string or default value.
+// Value may be slice/array of any element type, delimited string ("a,b,c") or JSON array string.
 func (o Options) StringSlice(key string, def ...string) []string {
-	val, ok := o[key]
-	if !ok || val == nil {
-		return def
-	}
-
-	t := reflect.TypeOf(val)
-	if t.Kind() != reflect.Slice {
-		return def
-	}
-
-	switch t.Elem().Kind() {
-	case reflect.String:
-		return val.([]string)
-	default:
-		rv := reflect.ValueOf(val)
-		n := rv.Len()
-		if n == 0 || !rv.Index(0).CanInterface() {
-			return nil
-		}
-
-		items := make([]string, 0, n)
-		for i := 0; i < n; i++ {
-			ev := rv.Index(i)
-			items = append(items, o.toString(ev.Interface(), ""))
-		}
-		return items
-	}
+	return convertItems(o, key, def, func(val interface{}) string {
+		return o.toString(val, "")
+	})
 }
 
 // FloatSlice return the value as given slice
 func (o Options) FloatSlice(key string, def ...float64) []float64 {
-	val, ok := o[key]
-	if !ok || val == nil {
-		return def
-	}
-
-	items := []float64{}
-	switch v := val.(type) {
-	case []float64:
-		return v
-	case []float32:
-		for _, sv := range v {
-			items = append(items, float64(sv))
-		}
-	case []int:
-		for _, sv := range v {
-			items = append(items, o.toFloat(sv, 0))
-		}
-	case []uint:
-		for _, sv := range v {
-			items = append(items, o.toFloat(sv, 0))
-		}
-	case []int64:
-		for _, sv := range v {
-			items = append(items, o.toFloat(sv, 0))
-		}
-	case []uint64:
-		for _, sv := range v {
-			items = append(items, o.toFloat(sv, 0))
-		}
-	case []int32:
-		for _, sv := range v {
-			items = append(items, float64(sv))
-		}
-	case []uint32:
-		for _, sv := range v {
-			items = append(items, float64(sv))
-		}
-	case []int16:
-		for _, sv := range v {
-			items = append(items, float64(sv))
-		}
-	case []uint16:
-		for _, sv := range v {
-			items = append(items, float64(sv))
-		}
-	case []int8:
-		for _, sv := range v {
-			items = append(items, float64(sv))
-		}
-	case []uint8:
-		for _, sv := range v {
-			items = append(items, float64(sv))
-		}
-	case []interface{}:
-		for _, sv := range v {
-			items = append(items, o.toFloat(sv, 0))
-		}
-	default:
-		return def
-	}
-
-	return items
+	return convertItems(o, key, def, func(val interface{}) float64 {
+		return o.toFloat(val, 0)
+	})
 }
 
 // IntSlice return the value as given slice
 func (o Options) IntSlice(key string, def ...int64) []int64 {
-	val, ok := o[key]
-	if !ok || val == nil {
-		return def
-	}
-
-	items := []int64{}
-	switch v := val.(type) {
-	case []int64:
-		return v
-	case []float64:
-		for _, sv := range v {
-			items = append(items, o.toInt(sv, 0))
-		}
-	case []float32:
-		for _, sv := range v {
-			items = append(items, o.toInt(sv, 0))
-		}
-	case []int:
-		for _, sv := range v {
-			items = append(items, int64(sv))
-		}
-	case []uint:
-		for _, sv := range v {
-			items = append(items, o.toInt(sv, 0))
-		}
-	case []uint64:
-		for _, sv := range v {
-			items = append(items, o.toInt(sv, 0))
-		}
-	case []int32:
-		for _, sv := range v {
-			items = append(items, int64(sv))
-		}
-	case []uint32:
-		for _, sv := range v {
-			items = append(items, int64(sv))
-		}
-	case []int16:
-		for _, sv := range v {
-			items = append(items, int64(sv))
-		}
-	case []uint16:
-		for _, sv := range v {
-			items = append(items, int64(sv))
-		}
-	case []int8:
-		for _, sv := range v {
-			items = append(items, int64(sv))
-		}
-	case []uint8:
-		for _, sv := range v {
-			items = append(items, int64(sv))
-		}
-	case []interface{}:
-		for _, sv := range v {
-			items = append(items, o.toInt(sv, 0))
-		}
-	default:
-		return def
-	}
+	return convertItems(o, key, def, func(val interface{}) int64 {
+		return o.toInt(val, 0)
+	})
+}
 
-	return items
+// UintSlice return the value as slice of unsigned integer
+func (o Options) UintSlice(key string, def ...uint64) []uint64 {
+	return convertItems(o, key, def, func(val interface{}) uint64 {
+		return o.toUint(val, 0)
+	})
 }
 
 // BoolSlice convert items into slice of boolean value.
 func (o Options) BoolSlice(key string, def ...bool) []bool {
-	val, ok := o[key]
-	if !ok || val == nil {
-		return def
-	}
+	return convertItems(o, key, def, func(val interface{}) bool {
+		return o.toBool(val, false)
+	})
+}
 
-	items := []bool{}
-	switch v := val.(type) {
-	case []bool:
-		return v
-	case []string:
-		for _, sv := range v {
-			items = append(items, o.toBool(sv, false))
-		}
-	case []interface{}:
-		for _, sv := range v {
-			items = append(items, o.toBool(sv, false))
-		}
-	default:
-		return def
-	}
+// DurationSlice convert items into slice of duration
+func (o Options) DurationSlice(key string, def ...time.Duration) []time.Duration {
+	return convertItems(o, key, def, func(val interface{}) time.Duration {
+		return o.toDuration(val, 0)
+	})
+}
 
-	return items
+// TimeSlice convert items into slice of time
+func (o Options) TimeSlice(key string, def ...time.Time) []time.Time {
+	return convertItems(o, key, def, func(val interface{}) time.Time {
+		return o.toTime(val, time.Time{})
+	})
 }
@@ -0,0 +1,184 @@
+package factory
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+func init() {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+
+	converters[reflect.TypeOf(int8(0))] = func(o Options, val interface{}) (interface{}, error) {
+		iv, err := o.asIntRange("int8", val, math.MinInt8, math.MaxInt8)
+		return int8(iv), err
+	}
+	converters[reflect.TypeOf(int16(0))] = func(o Options, val interface{}) (interface{}, error) {
+		iv, err := o.asIntRange("int16", val, math.MinInt16, math.MaxInt16)
+		return int16(iv), err
+	}
+	converters[reflect.TypeOf(int32(0))] = func(o Options, val interface{}) (interface{}, error) {
+		iv, err := o.asIntRange("int32", val, math.MinInt32, math.MaxInt32)
+		return int32(iv), err
+	}
+	converters[reflect.TypeOf(uint8(0))] = func(o Options, val interface{}) (interface{}, error) {
+		uv, err := o.asUintRange("uint8", val, 0, math.MaxUint8)
+		return uint8(uv), err
+	}
+	converters[reflect.TypeOf(uint16(0))] = func(o Options, val interface{}) (interface{}, error) {
+		uv, err := o.asUintRange("uint16", val, 0, math.MaxUint16)
+		return uint16(uv), err
+	}
+	converters[reflect.TypeOf(uint32(0))] = func(o Options, val interface{}) (interface{}, error) {
+		uv, err := o.asUintRange("uint32", val, 0, math.MaxUint32)
+		return uint32(uv), err
+	}
+
+	kindTypes[reflect.Int8] = reflect.TypeOf(int8(0))
+	kindTypes[reflect.Int16] = reflect.TypeOf(int16(0))
+	kindTypes[reflect.Int32] = reflect.TypeOf(int32(0))
+	kindTypes[reflect.Uint8] = reflect.TypeOf(uint8(0))
+	kindTypes[reflect.Uint16] = reflect.TypeOf(uint16(0))
+	kindTypes[reflect.Uint32] = reflect.TypeOf(uint32(0))
+}
+
+// asIntRange convert value to integer and check that it is within [min, max]
+func (o Options) asIntRange(typ string, val interface{}, min, max int64) (int64, error) {
+	if raw, ok := reveal(val); ok {
+		v, err := o.asIntRange(typ, raw, min, max)
+		return v, redact(err)
+	}
+	iv, err := o.asInt(val)
+	if err != nil {
+		return 0, invalidValue(typ, val, errorCause(err))
+	}
+	if iv < min || iv > max {
+		return 0, invalidValue(typ, val, fmt.Errorf("%w [%d, %d]", ErrOutOfRange, min, max))
+	}
+	return iv, nil
+}
+
+// asUintRange convert value to unsigned integer and check that it is within [min, max]
+func (o Options) asUintRange(typ string, val interface{}, min, max uint64) (uint64, error) {
+	if raw, ok := reveal(val); ok {
+		v, err := o.asUintRange(typ, raw, min, max)
+		return v, redact(err)
+	}
+	uv, err := o.asUint(val)
+	if err != nil {
+		return 0, invalidValue(typ, val, errorCause(err))
+	}
+	if uv < min || uv > max {
+		return 0, invalidValue(typ, val, fmt.Errorf("%w [%d, %d]", ErrOutOfRange, min, max))
+	}
+	return uv, nil
+}
+
+// Int8 return int8 value or default value if not exists, invalid or overflow
+func (o Options) Int8(key string, def ...int8) int8 {
+	return valueOf(o, key, def)
+}
+
+// Int16 return int16 value or default value if not exists, invalid or overflow
+func (o Options) Int16(key string, def ...int16) int16 {
+	return valueOf(o, key, def)
+}
+
+// Int32 return int32 value or default value if not exists, invalid or overflow
+func (o Options) Int32(key string, def ...int32) int32 {
+	return valueOf(o, key, def)
+}
+
+// Uint8 return uint8 value or default value if not exists, invalid or overflow
+func (o Options) Uint8(key string, def ...uint8) uint8 {
+	return valueOf(o, key, def)
+}
+
+// Uint16 return uint16 value or default value if not exists, invalid or overflow
+func (o Options) Uint16(key string, def ...uint16) uint16 {
+	return valueOf(o, key, def)
+}
+
+// Uint32 return uint32 value or default value if not exists, invalid or overflow
+func (o Options) Uint32(key string, def ...uint32) uint32 {
+	return valueOf(o, key, def)
+}
+
+// Port return TCP/UDP port number or default value if not exists, invalid or not within [1, 65535].
+// Use PortE to get the error.
+func (o Options) Port(key string, def ...uint16) uint16 {
+	p, _ := o.PortE(key, def...)
+	return p
+}
+
+// PortE return TCP/UDP port number that must be within [1, 65535].
+// If the key does not exist, default value is returned without error.
+// If the value is invalid or out of range, default value and *ValueError are returned.
+func (o Options) PortE(key string, def ...uint16) (uint16, error) {
+	var defV uint16
+	if len(def) > 0 {
+		defV = def[0]
+	}
+
+	val, ok := o[key]
+	if !ok || val == nil {
+		return defV, nil
+	}
+
+	uv, err := o.asUintRange("port", val, 1, math.MaxUint16)
+	if err != nil {
+		return defV, withKey(key, err)
+	}
+	return uint16(uv), nil
+}
+
+// IntInRange return integer value that must be within [min, max].
+// If the key does not exist, default value is returned without error.
+// If the value is invalid or out of range, default value and *ValueError are returned.
+// Error is returned as well if min is greater than max.
+func (o Options) IntInRange(key string, min, max int64, def ...int64) (int64, error) {
+	var defV int64
+	if len(def) > 0 {
+		defV = def[0]
+	}
+	if min > max {
+		return defV, fmt.Errorf("factory: IntInRange of %s: min %d is greater than max %d", key, min, max)
+	}
+
+	val, ok := o[key]
+	if !ok || val == nil {
+		return defV, nil
+	}
+
+	iv, err := o.asIntRange("int", val, min, max)
+	if err != nil {
+		return defV, withKey(key, err)
+	}
+	return iv, nil
+}
+
+// UintInRange return unsigned integer value that must be within [min, max].
+// If the key does not exist, default value is returned without error.
+// If the value is invalid or out of range, default value and *ValueError are returned.
+// Error is returned as well if min is greater than max.
+func (o Options) UintInRange(key string, min, max uint64, def ...uint64) (uint64, error) {
+	var defV uint64
+	if len(def) > 0 {
+		defV = def[0]
+	}
+	if min > max {
+		return defV, fmt.Errorf("factory: UintInRange of %s: min %d is greater than max %d", key, min, max)
+	}
+
+	val, ok := o[key]
+	if !ok || val == nil {
+		return defV, nil
+	}
+
+	uv, err := o.asUintRange("uint", val, min, max)
+	if err != nil {
+		return defV, withKey(key, err)
+	}
+	return uv, nil
+}
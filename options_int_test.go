@@ -0,0 +1,78 @@
+package factory_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNarrowIntegers(t *testing.T) {
+	op := factory.Options{
+		"small":    100,
+		"big":      300,
+		"negative": -1,
+		"port":     "8080",
+		"badport":  70000,
+		"retry":    20,
+	}
+
+	assert.Equal(t, int8(100), op.Int8("small"))
+	assert.Equal(t, int8(-1), op.Int8("big", -1), "Overflow return default")
+	assert.Equal(t, int16(300), op.Int16("big"))
+	assert.Equal(t, int32(-1), op.Int32("negative"))
+	assert.Equal(t, uint8(7), op.Uint8("negative", 7))
+	assert.Equal(t, uint32(300), op.Uint32("big"))
+	assert.Equal(t, uint16(8080), op.Port("port"))
+	assert.Equal(t, uint16(80), op.Port("badport", 80))
+
+	_, err := factory.Value[uint16](op, "badport")
+	assert.True(t, errors.Is(err, factory.ErrOutOfRange))
+	assert.Equal(t, "options.badport: invalid uint16 70000: value out of range [0, 65535]", err.Error())
+
+	_, err = factory.Value[int8](op, "big")
+	assert.True(t, errors.Is(err, factory.ErrOutOfRange))
+
+	n, err := op.IntInRange("retry", 0, 10, 3)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, "options.retry: invalid int 20: value out of range [0, 10]", err.Error())
+
+	n, err = op.IntInRange("small", 0, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), n)
+
+	u, err := op.UintInRange("missing", 1, 10, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), u)
+}
+
+func TestPortRange(t *testing.T) {
+	op := factory.Options{
+		"port":  8080,
+		"zero":  0,
+		"large": "70000",
+	}
+
+	p, err := op.PortE("port")
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(8080), p)
+
+	p, err = op.PortE("zero", 80)
+	assert.Equal(t, uint16(80), p)
+	assert.True(t, errors.Is(err, factory.ErrOutOfRange))
+	assert.Equal(t, "options.zero: invalid port 0: value out of range [1, 65535]", err.Error())
+	assert.Equal(t, uint16(443), op.Port("zero", 443), "Port 0 is invalid")
+
+	_, err = op.PortE("large")
+	assert.True(t, errors.Is(err, factory.ErrOutOfRange))
+
+	p, err = op.PortE("missing", 80)
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(80), p)
+
+	_, err = op.IntInRange("port", 10, 1)
+	assert.EqualError(t, err, "factory: IntInRange of port: min 10 is greater than max 1")
+	_, err = op.UintInRange("missing", 10, 1)
+	assert.EqualError(t, err, "factory: UintInRange of missing: min 10 is greater than max 1")
+}
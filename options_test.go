@@ -42,6 +42,9 @@ func TestOptions(t *testing.T) {
 			"IntSlice":    op.IntSlice(key),
 			"FloatSlice":  op.FloatSlice(key),
 			"BoolSlice":   op.BoolSlice(key),
+			"UintSlice":   op.UintSlice(key),
+			"Durations":   op.DurationSlice(key),
+			"TimeSlice":   op.TimeSlice(key),
 		}
 
 		fmt.Println("=== TEST KEY ", key, "===")
@@ -0,0 +1,384 @@
+package factory
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Supported time layout
+var tmLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05Z07:00",
+	"02/01/2006 15:04:05Z07:00",
+	time.RFC3339Nano,
+	time.RFC1123,
+	time.RFC1123Z,
+	time.RFC822,
+	time.RFC822Z,
+	time.RFC850,
+	time.ANSIC,
+	time.Layout,
+	time.RubyDate,
+	time.UnixDate,
+}
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+var (
+	timeMu       sync.RWMutex
+	timeLocation *time.Location
+	timeLayouts  []string
+)
+
+// SetTimeLocation sets default time zone.
+// It is used for layouts without zone information and for epoch values.
+// If loc is nil, time without zone is parsed as UTC and epoch is returned in local time.
+func SetTimeLocation(loc *time.Location) {
+	timeMu.Lock()
+	defer timeMu.Unlock()
+	timeLocation = loc
+}
+
+// RegisterTimeLayout registers layouts that will be tried before built-in layouts.
+func RegisterTimeLayout(layouts ...string) {
+	timeMu.Lock()
+	defer timeMu.Unlock()
+	timeLayouts = append(timeLayouts, layouts...)
+}
+
+// UnregisterTimeLayout removes layouts registered by RegisterTimeLayout
+func UnregisterTimeLayout(layouts ...string) {
+	timeMu.Lock()
+	defer timeMu.Unlock()
+	removed := make(map[string]bool, len(layouts))
+	for _, l := range layouts {
+		removed[l] = true
+	}
+	var res []string
+	for _, l := range timeLayouts {
+		if !removed[l] {
+			res = append(res, l)
+		}
+	}
+	timeLayouts = res
+}
+
+// timeSettings return configured location and layouts to be tried
+func timeSettings() (*time.Location, []string) {
+	timeMu.RLock()
+	defer timeMu.RUnlock()
+	layouts := make([]string, 0, len(timeLayouts)+len(tmLayouts))
+	layouts = append(layouts, timeLayouts...)
+	layouts = append(layouts, tmLayouts...)
+	return timeLocation, layouts
+}
+
+// ParseDuration parses duration string.
+// Beside time.ParseDuration syntax, it accepts days and weeks (e.g. "7d", "1w2d3h")
+// and ISO-8601 durations (e.g. "PT15M", "P1DT12H"). Years and months are not supported
+// since their length is not fixed.
+func ParseDuration(s string) (time.Duration, error) {
+	str := strings.TrimSpace(s)
+	if d, err := time.ParseDuration(str); err == nil {
+		return d, nil
+	}
+
+	body := str
+	neg := strings.HasPrefix(body, "-")
+	if neg || strings.HasPrefix(body, "+") {
+		body = body[1:]
+	}
+
+	var total float64
+	var ok bool
+	if strings.HasPrefix(body, "P") || strings.HasPrefix(body, "p") {
+		total, ok = parseISODuration(body[1:])
+	} else {
+		total, ok = parseUnitDuration(body)
+	}
+	if !ok {
+		return 0, invalidValue("duration", s, nil)
+	}
+	// float64(math.MaxInt64) is 2^63, which overflows too
+	if total >= math.MaxInt64 {
+		return 0, outOfRange("duration", s)
+	}
+	if neg {
+		total = -total
+	}
+	return time.Duration(total), nil
+}
+
+// parseUnitDuration parse sequence of number and unit, e.g. 1w2d3h4m
+func parseUnitDuration(s string) (float64, bool) {
+	var total float64
+	for s != "" {
+		num, unit, rest := splitNumUnit(s)
+		if num == "" || unit == "" {
+			return 0, false
+		}
+		var scale time.Duration
+		switch unit {
+		case "d":
+			scale = day
+		case "w":
+			scale = week
+		default:
+			d, err := time.ParseDuration("1" + unit)
+			if err != nil {
+				return 0, false
+			}
+			scale = d
+		}
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return 0, false
+		}
+		total += f * float64(scale)
+		s = rest
+	}
+	return total, true
+}
+
+// parseISODuration parse ISO-8601 duration without the leading P, e.g. 1DT12H
+func parseISODuration(s string) (float64, bool) {
+	var total float64
+	inTime := false
+	if s == "" {
+		return 0, false
+	}
+	for s != "" {
+		if s[0] == 'T' || s[0] == 't' {
+			if inTime || len(s) == 1 {
+				return 0, false
+			}
+			inTime = true
+			s = s[1:]
+			continue
+		}
+
+		num, unit, rest := splitNumUnit(s)
+		if num == "" || unit == "" {
+			return 0, false
+		}
+		// designator is single letter, e.g. T in 1DT12H belongs to the rest
+		unit, rest = unit[:1], unit[1:]+rest
+		var scale time.Duration
+		switch u := strings.ToUpper(unit); {
+		case !inTime && u == "W":
+			scale = week
+		case !inTime && u == "D":
+			scale = day
+		case inTime && u == "H":
+			scale = time.Hour
+		case inTime && u == "M":
+			scale = time.Minute
+		case inTime && u == "S":
+			scale = time.Second
+		default:
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(strings.Replace(num, ",", ".", 1), 64)
+		if err != nil {
+			return 0, false
+		}
+		total += f * float64(scale)
+		s = rest
+	}
+	return total, true
+}
+
+// splitNumUnit split leading number and unit from s.
+func splitNumUnit(s string) (num, unit, rest string) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !(unicode.IsDigit(r) || r == '.' || r == ',')
+	})
+	if i < 0 {
+		return s, "", ""
+	}
+	j := strings.IndexFunc(s[i:], func(r rune) bool {
+		return unicode.IsDigit(r) || r == '.'
+	})
+	if j < 0 {
+		return s[:i], s[i:], ""
+	}
+	return s[:i], s[i : i+j], s[i+j:]
+}
+
+// asDuration convert value to duration or return error
+func (o Options) asDuration(val interface{}) (time.Duration, error) {
+	switch v := val.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		return ParseDuration(v)
+	case fmt.Stringer:
+		d, err := ParseDuration(v.String())
+		if err != nil {
+			return 0, invalidValue("duration", val, nil)
+		}
+		return d, nil
+	default:
+		iv, err := o.asInt(val)
+		if err != nil {
+			return 0, invalidValue("duration", val, nil)
+		}
+		return time.Duration(iv), nil
+	}
+}
+
+// epochTime convert epoch value into time.
+// Unit is detected from magnitude: seconds, milliseconds, microseconds or nanoseconds.
+func epochTime(v int64, loc *time.Location) time.Time {
+	abs := v
+	if abs < 0 {
+		abs = -abs
+	}
+
+	var tm time.Time
+	switch {
+	case abs < 1e11:
+		tm = time.Unix(v, 0)
+	case abs < 1e14:
+		tm = time.UnixMilli(v)
+	case abs < 1e17:
+		tm = time.UnixMicro(v)
+	default:
+		tm = time.Unix(0, v)
+	}
+	if loc != nil {
+		return tm.In(loc)
+	}
+	return tm
+}
+
+// epochFloat convert fractional epoch seconds into time
+func epochFloat(v float64, loc *time.Location) (time.Time, bool) {
+	if math.IsNaN(v) || math.IsInf(v, 0) || math.Abs(v) >= 1e11 {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(v)
+	tm := time.Unix(int64(sec), int64(frac*1e9))
+	if loc != nil {
+		return tm.In(loc), true
+	}
+	return tm, true
+}
+
+// parseTime parse time string.
+// Given layouts are tried first, then relative time (e.g. "now-1h"), epoch and registered layouts.
+func (o Options) parseTime(str string, layouts ...string) (time.Time, error) {
+	loc, known := timeSettings()
+	parseLoc := loc
+	if parseLoc == nil {
+		parseLoc = time.UTC
+	}
+
+	s := strings.TrimSpace(str)
+	for _, layout := range layouts {
+		if tm, err := time.ParseInLocation(layout, s, parseLoc); err == nil {
+			return tm, nil
+		}
+	}
+
+	// relative to current time
+	if len(s) >= 3 && strings.EqualFold(s[:3], "now") {
+		now := time.Now()
+		if loc != nil {
+			now = now.In(loc)
+		}
+		rel := strings.TrimSpace(s[3:])
+		if rel == "" {
+			return now, nil
+		}
+		if rel[0] == '+' || rel[0] == '-' {
+			if d, err := ParseDuration(rel); err == nil {
+				return now.Add(d), nil
+			}
+		}
+		return time.Time{}, invalidValue("time", str, nil)
+	}
+
+	// epoch
+	if iv, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return epochTime(iv, loc), nil
+	}
+	if fv, err := strconv.ParseFloat(s, 64); err == nil {
+		if tm, ok := epochFloat(fv, loc); ok {
+			return tm, nil
+		}
+	}
+
+	for _, layout := range known {
+		if tm, err := time.ParseInLocation(layout, s, parseLoc); err == nil {
+			return tm, nil
+		}
+	}
+	return time.Time{}, invalidValue("time", str, nil)
+}
+
+// asTime convert interface value to time.Time or return error
+func (o Options) asTime(val interface{}, layouts ...string) (time.Time, error) {
+	switch v := val.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return o.parseTime(v, layouts...)
+	case fmt.Stringer:
+		return o.parseTime(v.String(), layouts...)
+	case float64:
+		if _, frac := math.Modf(v); frac != 0 {
+			return o.asEpochFloat(val, v)
+		}
+	case float32:
+		if _, frac := math.Modf(float64(v)); frac != 0 {
+			return o.asEpochFloat(val, float64(v))
+		}
+	}
+
+	// get from timestamp
+	iv, err := o.asInt(val)
+	if err != nil {
+		return time.Time{}, invalidValue("time", val, nil)
+	}
+	loc, _ := timeSettings()
+	return epochTime(iv, loc), nil
+}
+
+// asEpochFloat convert fractional seconds into time
+func (o Options) asEpochFloat(val interface{}, v float64) (time.Time, error) {
+	loc, _ := timeSettings()
+	if tm, ok := epochFloat(v, loc); ok {
+		return tm, nil
+	}
+	return time.Time{}, invalidValue("time", val, nil)
+}
+
+// TimeLayout return time.Time parsed using given layouts.
+// If none of the layouts match, value is parsed as in Time.
+func (o Options) TimeLayout(key string, layouts []string, def ...time.Time) time.Time {
+	var defV time.Time
+	if len(def) > 0 {
+		defV = def[0]
+	}
+
+	val, ok := o[key]
+	if !ok || val == nil {
+		return defV
+	}
+
+	val, _ = reveal(val)
+	tm, err := o.asTime(val, layouts...)
+	if err != nil {
+		return defV
+	}
+	return tm
+}
@@ -0,0 +1,79 @@
+package factory_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"15m":      15 * time.Minute,
+		"7d":       7 * 24 * time.Hour,
+		"1w2d":     9 * 24 * time.Hour,
+		"1d12h30m": 36*time.Hour + 30*time.Minute,
+		"-2d":      -48 * time.Hour,
+		"PT15M":    15 * time.Minute,
+		"P1DT12H":  36 * time.Hour,
+		"P2W":      14 * 24 * time.Hour,
+		"PT1.5S":   1500 * time.Millisecond,
+	}
+	for s, exp := range tests {
+		d, err := factory.ParseDuration(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, exp, d, s)
+	}
+
+	for _, s := range []string{"15mins", "P1Y", "PT", "P1H", "d", "1x"} {
+		_, err := factory.ParseDuration(s)
+		assert.NotNil(t, err, s)
+	}
+
+	for _, s := range []string{"9223372036854775808ns", "106752d", "P15251W"} {
+		_, err := factory.ParseDuration(s)
+		assert.True(t, errors.Is(err, factory.ErrOutOfRange), s)
+	}
+}
+
+func TestTimeParsing(t *testing.T) {
+	exp := time.Date(2022, 1, 2, 8, 14, 0, 0, time.UTC)
+	op := factory.Options{
+		"sec":    exp.Unix(),
+		"ms":     exp.UnixMilli(),
+		"us":     exp.UnixMicro(),
+		"ns":     exp.UnixNano(),
+		"str":    "1641111240000",
+		"rel":    "now-1h",
+		"layout": "2022/01/02 08:14",
+		"local":  "2022-01-02 15:14:00",
+	}
+
+	for _, key := range []string{"sec", "ms", "us", "ns", "str"} {
+		assert.True(t, exp.Equal(op.Time(key)), key)
+	}
+
+	rel := op.Time("rel")
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), rel, time.Minute)
+
+	assert.True(t, op.Time("layout").IsZero())
+	assert.True(t, exp.Equal(op.TimeLayout("layout", []string{"2006/01/02 15:04"})))
+
+	d := factory.Options{"d": "1w"}.Duration("d")
+	assert.Equal(t, 7*24*time.Hour, d)
+
+	jakarta := time.FixedZone("WIB", 7*3600)
+	factory.RegisterTimeLayout("2006-01-02 15:04:05")
+	defer factory.UnregisterTimeLayout("2006-01-02 15:04:05")
+	factory.SetTimeLocation(jakarta)
+	defer factory.SetTimeLocation(nil)
+
+	tm := op.Time("local")
+	assert.True(t, exp.Equal(tm), "Time without zone uses default location")
+	assert.Equal(t, jakarta, op.Time("sec").Location())
+
+	factory.UnregisterTimeLayout("2006-01-02 15:04:05")
+	assert.True(t, op.Time("local").IsZero(), "Unregistered layout shall not be used")
+}
@@ -0,0 +1,459 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ByteSize represents size in bytes, e.g. parsed from "512MiB" or "1.5GB"
+type ByteSize uint64
+
+// Byte size units
+const (
+	Byte ByteSize = 1
+	KB   ByteSize = 1000 * Byte
+	MB   ByteSize = 1000 * KB
+	GB   ByteSize = 1000 * MB
+	TB   ByteSize = 1000 * GB
+	PB   ByteSize = 1000 * TB
+	EB   ByteSize = 1000 * PB
+	KiB  ByteSize = 1 << 10
+	MiB  ByteSize = 1 << 20
+	GiB  ByteSize = 1 << 30
+	TiB  ByteSize = 1 << 40
+	PiB  ByteSize = 1 << 50
+	EiB  ByteSize = 1 << 60
+)
+
+// size unit suffixes, lower case
+var sizeUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"k":   KiB,
+	"kb":  KB,
+	"kib": KiB,
+	"m":   MiB,
+	"mb":  MB,
+	"mib": MiB,
+	"g":   GiB,
+	"gb":  GB,
+	"gib": GiB,
+	"t":   TiB,
+	"tb":  TB,
+	"tib": TiB,
+	"p":   PiB,
+	"pb":  PB,
+	"pib": PiB,
+	"e":   EiB,
+	"eb":  EB,
+	"eib": EiB,
+}
+
+// String return size using largest binary unit that represents it exactly
+func (b ByteSize) String() string {
+	units := []struct {
+		size ByteSize
+		name string
+	}{
+		{EiB, "EiB"}, {PiB, "PiB"}, {TiB, "TiB"}, {GiB, "GiB"}, {MiB, "MiB"}, {KiB, "KiB"},
+	}
+	for _, u := range units {
+		if b >= u.size && b%u.size == 0 {
+			return strconv.FormatUint(uint64(b/u.size), 10) + u.name
+		}
+	}
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
+
+// ParseByteSize parse size such as "512MiB", "1.5GB" or "1024".
+// Single letter unit (K, M, G, ...) is treated as binary unit.
+func ParseByteSize(s string) (ByteSize, error) {
+	str := strings.TrimSpace(s)
+	i := strings.IndexFunc(str, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.')
+	})
+	num, unit := str, ""
+	if i >= 0 {
+		num, unit = str[:i], strings.TrimSpace(str[i:])
+	}
+
+	mul, ok := sizeUnits[strings.ToLower(unit)]
+	if !ok || num == "" {
+		return 0, invalidValue("size", s, nil)
+	}
+	if u, err := strconv.ParseUint(num, 10, 64); err == nil {
+		if u > math.MaxUint64/uint64(mul) {
+			return 0, outOfRange("size", s)
+		}
+		return ByteSize(u) * mul, nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, invalidValue("size", s, err)
+	}
+	f *= float64(mul)
+	if f >= math.MaxUint64 {
+		return 0, outOfRange("size", s)
+	}
+	return ByteSize(f), nil
+}
+
+// HostPort holds host and port pair, e.g. parsed from "localhost:8080"
+type HostPort struct {
+	Host string
+	Port uint16
+}
+
+// String return host:port representation
+func (h HostPort) String() string {
+	return net.JoinHostPort(h.Host, strconv.Itoa(int(h.Port)))
+}
+
+// ParseHostPort parse "host:port" or "[ipv6]:port" string.
+func ParseHostPort(s string) (HostPort, error) {
+	host, port, err := net.SplitHostPort(strings.TrimSpace(s))
+	if err != nil {
+		return HostPort{}, invalidValue("host:port", s, nil)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return HostPort{}, invalidValue("host:port", s, err)
+	}
+	return HostPort{Host: host, Port: uint16(p)}, nil
+}
+
+func init() {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+
+	converters[reflect.TypeOf(ByteSize(0))] = func(o Options, val interface{}) (interface{}, error) {
+		return o.asByteSize(val)
+	}
+	converters[reflect.TypeOf(HostPort{})] = func(o Options, val interface{}) (interface{}, error) {
+		return o.asHostPort(val)
+	}
+	converters[reflect.TypeOf(&url.URL{})] = func(o Options, val interface{}) (interface{}, error) {
+		return o.asURL(val)
+	}
+	converters[reflect.TypeOf(net.IP{})] = func(o Options, val interface{}) (interface{}, error) {
+		return o.asIP(val)
+	}
+	converters[reflect.TypeOf(&net.IPNet{})] = func(o Options, val interface{}) (interface{}, error) {
+		return o.asCIDR(val)
+	}
+	converters[reflect.TypeOf(&regexp.Regexp{})] = func(o Options, val interface{}) (interface{}, error) {
+		return o.asRegexp(val)
+	}
+	converters[reflect.TypeOf(fs.FileMode(0))] = func(o Options, val interface{}) (interface{}, error) {
+		return o.asFileMode(val)
+	}
+	converters[reflect.TypeOf(&time.Location{})] = func(o Options, val interface{}) (interface{}, error) {
+		return o.asLocation(val)
+	}
+	converters[reflect.TypeOf(map[string]string{})] = func(o Options, val interface{}) (interface{}, error) {
+		return o.asStringMap(val)
+	}
+}
+
+// asByteSize convert string or number to byte size
+func (o Options) asByteSize(val interface{}) (ByteSize, error) {
+	switch v := val.(type) {
+	case ByteSize:
+		return v, nil
+	case string:
+		return ParseByteSize(v)
+	case fmt.Stringer:
+		return ParseByteSize(v.String())
+	}
+	u, err := o.asUint(val)
+	if err != nil {
+		return 0, invalidValue("size", val, nil)
+	}
+	return ByteSize(u), nil
+}
+
+// asHostPort convert string to host and port pair
+func (o Options) asHostPort(val interface{}) (HostPort, error) {
+	if v, ok := val.(HostPort); ok {
+		return v, nil
+	}
+	s, err := o.asString(val)
+	if err != nil {
+		return HostPort{}, invalidValue("host:port", val, nil)
+	}
+	return ParseHostPort(s)
+}
+
+// asURL convert string to URL
+func (o Options) asURL(val interface{}) (*url.URL, error) {
+	switch v := val.(type) {
+	case *url.URL:
+		return v, nil
+	case url.URL:
+		return &v, nil
+	}
+	s, err := o.asString(val)
+	if err != nil {
+		return nil, invalidValue("url", val, nil)
+	}
+	s = strings.TrimSpace(s)
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, invalidValue("url", val, nil)
+	}
+	if u.Scheme == "" {
+		return nil, invalidValue("url", val, errors.New("missing scheme"))
+	}
+	// absolute URL with authority, e.g. https://host/path, requires host (file:///path has none)
+	if u.Host == "" && u.Scheme != "file" && strings.HasPrefix(s[len(u.Scheme)+1:], "//") {
+		return nil, invalidValue("url", val, errors.New("missing host"))
+	}
+	return u, nil
+}
+
+// asIP convert string to IP address
+func (o Options) asIP(val interface{}) (net.IP, error) {
+	if v, ok := val.(net.IP); ok {
+		return v, nil
+	}
+	s, err := o.asString(val)
+	if err != nil {
+		return nil, invalidValue("ip", val, nil)
+	}
+	ip := net.ParseIP(strings.TrimSpace(s))
+	if ip == nil {
+		return nil, invalidValue("ip", val, nil)
+	}
+	return ip, nil
+}
+
+// asCIDR convert string to IP network
+func (o Options) asCIDR(val interface{}) (*net.IPNet, error) {
+	switch v := val.(type) {
+	case *net.IPNet:
+		return v, nil
+	case net.IPNet:
+		return &v, nil
+	}
+	s, err := o.asString(val)
+	if err != nil {
+		return nil, invalidValue("cidr", val, nil)
+	}
+	_, ipNet, err := net.ParseCIDR(strings.TrimSpace(s))
+	if err != nil {
+		return nil, invalidValue("cidr", val, nil)
+	}
+	return ipNet, nil
+}
+
+// asRegexp compile string as regular expression
+func (o Options) asRegexp(val interface{}) (*regexp.Regexp, error) {
+	if v, ok := val.(*regexp.Regexp); ok {
+		return v, nil
+	}
+	s, err := o.asString(val)
+	if err != nil {
+		return nil, invalidValue("regexp", val, nil)
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, invalidValue("regexp", val, err)
+	}
+	return re, nil
+}
+
+// fileModeBits are bits accepted in file mode option
+const fileModeBits = fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky
+
+// asFileMode convert octal string (e.g. "0644") or number to file mode.
+// Number is the mode itself, e.g. 0644 in Go or YAML, or 420 in JSON.
+func (o Options) asFileMode(val interface{}) (fs.FileMode, error) {
+	switch v := val.(type) {
+	case fs.FileMode:
+		return v, nil
+	case string:
+		m, err := strconv.ParseUint(strings.TrimSpace(v), 8, 32)
+		if err != nil {
+			return 0, invalidValue("file mode", val, err)
+		}
+		return checkFileMode(val, fs.FileMode(m))
+	}
+	u, err := o.asUint(val)
+	if err != nil || u > math.MaxUint32 {
+		return 0, invalidValue("file mode", val, nil)
+	}
+	return checkFileMode(val, fs.FileMode(u))
+}
+
+// checkFileMode reject mode with bits other than permission, setuid, setgid and sticky
+func checkFileMode(val interface{}, m fs.FileMode) (fs.FileMode, error) {
+	if m&^fileModeBits != 0 {
+		return 0, invalidValue("file mode", val, errors.New("unknown mode bits"))
+	}
+	return m, nil
+}
+
+// asLocation load time zone by name, e.g. "Asia/Jakarta" or "UTC"
+func (o Options) asLocation(val interface{}) (*time.Location, error) {
+	if v, ok := val.(*time.Location); ok {
+		return v, nil
+	}
+	s, err := o.asString(val)
+	if err != nil {
+		return nil, invalidValue("location", val, nil)
+	}
+	loc, err := time.LoadLocation(strings.TrimSpace(s))
+	if err != nil {
+		return nil, invalidValue("location", val, nil)
+	}
+	return loc, nil
+}
+
+// asStringMap convert map or "k1=v1,k2=v2" string to map of string
+func (o Options) asStringMap(val interface{}) (map[string]string, error) {
+	switch v := val.(type) {
+	case map[string]string:
+		return v, nil
+	case string:
+		res := map[string]string{}
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			kv := strings.SplitN(item, "=", 2)
+			if len(kv) != 2 {
+				return nil, invalidValue("string map", val, nil)
+			}
+			res[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+		return res, nil
+	}
+
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, invalidValue("string map", val, nil)
+	}
+	res := make(map[string]string, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		s, err := o.asString(iter.Value().Interface())
+		if err != nil {
+			return nil, withKey(key, err)
+		}
+		res[key] = s
+	}
+	return res, nil
+}
+
+// valueOf return converted value or default if not exists/invalid
+func valueOf[T any](o Options, key string, def []T) T {
+	v, _ := Value(o, key, def...)
+	return v
+}
+
+// sliceOf return converted slice or default if not exists/invalid
+func sliceOf[T any](o Options, key string, def []T) []T {
+	v, _ := Slice(o, key, def...)
+	return v
+}
+
+// Size return byte size such as "512MiB" or default value if specified
+func (o Options) Size(key string, def ...ByteSize) ByteSize {
+	return valueOf(o, key, def)
+}
+
+// URL return parsed URL or default value if specified
+func (o Options) URL(key string, def ...*url.URL) *url.URL {
+	return valueOf(o, key, def)
+}
+
+// IP return IP address or default value if specified
+func (o Options) IP(key string, def ...net.IP) net.IP {
+	return valueOf(o, key, def)
+}
+
+// CIDR return IP network such as "10.0.0.0/8" or default value if specified
+func (o Options) CIDR(key string, def ...*net.IPNet) *net.IPNet {
+	return valueOf(o, key, def)
+}
+
+// HostPort return host and port pair or default value if specified
+func (o Options) HostPort(key string, def ...HostPort) HostPort {
+	return valueOf(o, key, def)
+}
+
+// Regexp return compiled regular expression or default value if specified
+func (o Options) Regexp(key string, def ...*regexp.Regexp) *regexp.Regexp {
+	return valueOf(o, key, def)
+}
+
+// FileMode return file mode such as "0644" (octal) or default value if specified
+func (o Options) FileMode(key string, def ...fs.FileMode) fs.FileMode {
+	return valueOf(o, key, def)
+}
+
+// Location return time zone such as "Asia/Jakarta" or default value if specified
+func (o Options) Location(key string, def ...*time.Location) *time.Location {
+	return valueOf(o, key, def)
+}
+
+// StringMap return map of string or default value if specified.
+// Value may be a map or string in "k1=v1,k2=v2" format.
+func (o Options) StringMap(key string, def ...map[string]string) map[string]string {
+	return valueOf(o, key, def)
+}
+
+// SizeSlice return slice of byte size or default value
+func (o Options) SizeSlice(key string, def ...ByteSize) []ByteSize {
+	return sliceOf(o, key, def)
+}
+
+// URLSlice return slice of URL or default value
+func (o Options) URLSlice(key string, def ...*url.URL) []*url.URL {
+	return sliceOf(o, key, def)
+}
+
+// IPSlice return slice of IP address or default value
+func (o Options) IPSlice(key string, def ...net.IP) []net.IP {
+	return sliceOf(o, key, def)
+}
+
+// CIDRSlice return slice of IP network or default value
+func (o Options) CIDRSlice(key string, def ...*net.IPNet) []*net.IPNet {
+	return sliceOf(o, key, def)
+}
+
+// HostPortSlice return slice of host and port pair or default value
+func (o Options) HostPortSlice(key string, def ...HostPort) []HostPort {
+	return sliceOf(o, key, def)
+}
+
+// RegexpSlice return slice of compiled regular expression or default value
+func (o Options) RegexpSlice(key string, def ...*regexp.Regexp) []*regexp.Regexp {
+	return sliceOf(o, key, def)
+}
+
+// FileModeSlice return slice of file mode or default value
+func (o Options) FileModeSlice(key string, def ...fs.FileMode) []fs.FileMode {
+	return sliceOf(o, key, def)
+}
+
+// LocationSlice return slice of time zone or default value
+func (o Options) LocationSlice(key string, def ...*time.Location) []*time.Location {
+	return sliceOf(o, key, def)
+}
+
+// StringMapSlice return slice of string map or default value
+func (o Options) StringMapSlice(key string, def ...map[string]string) []map[string]string {
+	return sliceOf(o, key, def)
+}
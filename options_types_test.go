@@ -0,0 +1,105 @@
+package factory_test
+
+import (
+	"io/fs"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]factory.ByteSize{
+		"1024":    1024,
+		"512MiB":  512 * factory.MiB,
+		"1.5GB":   1500 * factory.MB,
+		"2k":      2048,
+		" 10 KB ": 10000,
+	}
+	for s, exp := range tests {
+		b, err := factory.ParseByteSize(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, exp, b, s)
+	}
+
+	_, err := factory.ParseByteSize("12XB")
+	assert.NotNil(t, err)
+	_, err = factory.ParseByteSize("20EiB")
+	assert.ErrorIs(t, err, factory.ErrOutOfRange)
+	assert.Equal(t, "512MiB", (512 * factory.MiB).String())
+}
+
+func TestOptionTypes(t *testing.T) {
+	op := factory.Options{
+		"size":  "512MiB",
+		"sizes": []interface{}{"1KiB", 10},
+		"url":   "https://example.com/path?q=1",
+		"ip":    "192.168.1.10",
+		"cidr":  "10.0.0.0/8",
+		"addr":  "localhost:8080",
+		"addrs": "[::1]:80",
+		"re":    "^item[0-9]+$",
+		"mode":  "0644",
+		"loc":   "UTC",
+		"map":   "a=1, b=2",
+		"meta":  map[string]interface{}{"x": 1, "y": true},
+		"port":  "localhost:70000",
+	}
+
+	assert.Equal(t, 512*factory.MiB, op.Size("size"))
+	assert.Equal(t, []factory.ByteSize{1024, 10}, op.SizeSlice("sizes"))
+	assert.Equal(t, "example.com", op.URL("url").Host)
+	assert.True(t, op.IP("ip").Equal(net.IPv4(192, 168, 1, 10)))
+	assert.True(t, op.CIDR("cidr").Contains(net.IPv4(10, 1, 2, 3)))
+	assert.Equal(t, factory.HostPort{Host: "localhost", Port: 8080}, op.HostPort("addr"))
+	assert.Equal(t, "[::1]:80", op.HostPort("addrs").String())
+	assert.True(t, op.Regexp("re").MatchString("item12"))
+	assert.Equal(t, fs.FileMode(0644), op.FileMode("mode"))
+	assert.Equal(t, time.UTC, op.Location("loc"))
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, op.StringMap("map"))
+	assert.Equal(t, map[string]string{"x": "1", "y": "true"}, op.StringMap("meta"))
+
+	// default semantics
+	def := factory.HostPort{Host: "0.0.0.0", Port: 80}
+	assert.Equal(t, def, op.HostPort("port", def), "Port out of range")
+	assert.Equal(t, def, op.HostPort("missing", def))
+	assert.Nil(t, op.IP("url"))
+	assert.Equal(t, fs.FileMode(0600), op.FileMode("missing", 0600))
+}
+
+func TestFileModeAndURL(t *testing.T) {
+	op := factory.Options{
+		"octal":    0644,
+		"json":     float64(420),
+		"digits":   "0755",
+		"bad":      "9",
+		"dir":      int(fs.ModeDir | 0755),
+		"relative": "example.com/path",
+		"nohost":   "https:///path",
+		"file":     "file:///var/run/app.sock",
+		"mailto":   "mailto:admin@example.com",
+	}
+	assert.Equal(t, fs.FileMode(0644), op.FileMode("octal"), "Number shall be the mode itself")
+	assert.Equal(t, fs.FileMode(0644), op.FileMode("json"))
+	assert.Equal(t, fs.FileMode(0755), op.FileMode("digits"))
+	_, err := factory.Value[fs.FileMode](op, "bad")
+	assert.Error(t, err)
+	_, err = factory.Value[fs.FileMode](op, "dir")
+	assert.Error(t, err)
+
+	var yop factory.Options
+	assert.NoError(t, yaml.Unmarshal([]byte("mode: 0644\n"), &yop))
+	assert.Equal(t, fs.FileMode(0644), yop.FileMode("mode"), "YAML octal")
+
+	_, err = factory.Value[*url.URL](op, "relative")
+	assert.EqualError(t, err, `options.relative: invalid url "example.com/path": missing scheme`)
+	_, err = factory.Value[*url.URL](op, "nohost")
+	assert.EqualError(t, err, `options.nohost: invalid url "https:///path": missing host`)
+	assert.Nil(t, op.URL("relative"))
+	assert.Equal(t, "/var/run/app.sock", op.URL("file").Path)
+	assert.Equal(t, "mailto", op.URL("mailto").Scheme)
+}
@@ -1,7 +1,9 @@
 package factory
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
 )
 
@@ -24,6 +26,21 @@ func register(name string, factory *Factory) {
 	factories[name] = factory
 }
 
+// Unregister removes factory with given name from the registry.
+// Objects already created by the factory are not affected.
+// It return false if the factory is not registered.
+func Unregister(name string) bool {
+	factoriesMu.Lock()
+	f, ok := factories[name]
+	delete(factories, name)
+	factoriesMu.Unlock()
+
+	if ok {
+		emit(f.event(EventUnregistered))
+	}
+	return ok
+}
+
 // Factories returns a sorted list of the names of the registered factories.
 func Factories() []*Factory {
 	factoriesMu.RLock()
@@ -51,3 +68,25 @@ func Get(name string) *Factory {
 	}
 	return nil
 }
+
+// RequireFactories checks that factories with given names are registered.
+// It is meant to be called at startup so that missing blank imports are reported
+// before any object is created. Error lists every missing factory.
+func RequireFactories(names ...string) error {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	var missing []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		if _, ok := factories[name]; !ok && !seen[name] {
+			missing = append(missing, name)
+			seen[name] = true
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (import the packages registering them, e.g. impl/all)",
+		ErrMissingFactory, strings.Join(missing, ", "))
+}
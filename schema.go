@@ -0,0 +1,273 @@
+package factory
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OptionSpec describes option accepted by factory constructor.
+type OptionSpec struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Default     interface{} `json:"default,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Secret      bool        `json:"secret,omitempty"`
+}
+
+var (
+	optionTypesMu sync.RWMutex
+	optionTypes   = map[string]reflect.Type{
+		"string":   reflect.TypeOf(""),
+		"bool":     reflect.TypeOf(false),
+		"int":      reflect.TypeOf(int64(0)),
+		"int8":     reflect.TypeOf(int8(0)),
+		"int16":    reflect.TypeOf(int16(0)),
+		"int32":    reflect.TypeOf(int32(0)),
+		"uint":     reflect.TypeOf(uint64(0)),
+		"uint8":    reflect.TypeOf(uint8(0)),
+		"uint16":   reflect.TypeOf(uint16(0)),
+		"uint32":   reflect.TypeOf(uint32(0)),
+		"port":     reflect.TypeOf(uint16(0)),
+		"float":    reflect.TypeOf(float64(0)),
+		"duration": reflect.TypeOf(time.Duration(0)),
+		"time":     reflect.TypeOf(time.Time{}),
+		"size":     reflect.TypeOf(ByteSize(0)),
+		"url":      reflect.TypeOf(&url.URL{}),
+		"ip":       reflect.TypeOf(net.IP{}),
+		"cidr":     reflect.TypeOf(&net.IPNet{}),
+		"hostport": reflect.TypeOf(HostPort{}),
+		"regexp":   reflect.TypeOf(&regexp.Regexp{}),
+		"filemode": reflect.TypeOf(fs.FileMode(0)),
+		"location": reflect.TypeOf(&time.Location{}),
+		"map":      reflect.TypeOf(map[string]string{}),
+	}
+
+	// types named after their Go type by optionTypeName, kept apart from registered types
+	derivedTypesMu sync.RWMutex
+	derivedTypes   = map[string]reflect.Type{}
+
+	// canonicalTypes are names of built-in types used by optionTypeName
+	canonicalTypes = canonicalNames()
+)
+
+// optionChecks are additional checks done by Validate for type names sharing Go type
+// with another name, e.g. port is uint16 within [1, 65535]
+var optionChecks = map[string]func(o Options, val interface{}) error{
+	"port": func(o Options, val interface{}) error {
+		_, err := o.asUintRange("port", val, 1, math.MaxUint16)
+		return err
+	},
+}
+
+// canonicalNames return built-in type names by type, names with additional checks are excluded
+func canonicalNames() map[reflect.Type]string {
+	names := make(map[reflect.Type]string, len(optionTypes))
+	for name, t := range optionTypes {
+		if _, ok := optionChecks[name]; !ok {
+			names[t] = name
+		}
+	}
+	return names
+}
+
+// RegisterOptionType registers type name that can be used in OptionSpec.Type.
+// Slice of registered type is written as []name, e.g. []duration.
+func RegisterOptionType(name string, t reflect.Type) {
+	if t == nil {
+		panic("factory: RegisterOptionType type is nil")
+	}
+	optionTypesMu.Lock()
+	defer optionTypesMu.Unlock()
+	optionTypes[name] = t
+}
+
+// OptionType return reflect type of option type name, e.g. duration or []string.
+// Empty name is string, unknown name return false.
+func OptionType(name string) (reflect.Type, bool) {
+	if name == "" {
+		return reflect.TypeOf(""), true
+	}
+	if strings.HasPrefix(name, "[]") {
+		et, ok := OptionType(name[2:])
+		if !ok {
+			return nil, false
+		}
+		return reflect.SliceOf(et), true
+	}
+
+	optionTypesMu.RLock()
+	t, ok := optionTypes[name]
+	optionTypesMu.RUnlock()
+	if ok {
+		return t, true
+	}
+
+	derivedTypesMu.RLock()
+	defer derivedTypesMu.RUnlock()
+	t, ok = derivedTypes[name]
+	return t, ok
+}
+
+// optionTypeName return option type name of reflect type.
+// Built-in type has its canonical name, e.g. uint16 and never port.
+// Unknown type is named after its Go type, the name is resolved by OptionType
+// without registering the type (see RegisterOptionType).
+func optionTypeName(t reflect.Type) string {
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		return "[]" + optionTypeName(t.Elem())
+	}
+
+	if name, ok := canonicalTypes[t]; ok {
+		return name
+	}
+	optionTypesMu.RLock()
+	best := ""
+	for name, ot := range optionTypes {
+		// registered type, pick the same name on every call
+		if ot == t && (best == "" || name < best) {
+			best = name
+		}
+	}
+	optionTypesMu.RUnlock()
+	if best != "" {
+		return best
+	}
+	if base, ok := kindTypes[t.Kind()]; ok && base == t {
+		// int, uint are stored as 64-bit
+		return t.Kind().String()
+	}
+
+	derivedTypesMu.Lock()
+	defer derivedTypesMu.Unlock()
+	derivedTypes[t.String()] = t
+	return t.String()
+}
+
+// Spec return specification of option with given name
+func (f *Factory) Spec(name string) (OptionSpec, bool) {
+	for _, spec := range f.info.Options {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return OptionSpec{}, false
+}
+
+// StructSpecs derives option specifications from struct fields.
+// Option name is taken from `option` tag (or field name), description from `help` tag,
+// `required:"true"` marks required option, `secret:"true"` marks secret option
+// and current field value is used as default.
+// Nested struct produces options with dotted name, e.g. db.host.
+func StructSpecs(v interface{}) ([]OptionSpec, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("factory: StructSpecs requires struct, got %T", v)
+	}
+	return structSpecs(rv, ""), nil
+}
+
+// structSpecs collect specs of struct fields
+func structSpecs(sv reflect.Value, prefix string) []OptionSpec {
+	var specs []OptionSpec
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := fieldName(sf)
+		if name == "-" {
+			continue
+		}
+		name = joinKey(prefix, name)
+
+		fv := sv.Field(i)
+		if sf.Type.Kind() == reflect.Struct && !Options(nil).convertible(sf.Type) {
+			specs = append(specs, structSpecs(fv, name)...)
+			continue
+		}
+
+		spec := OptionSpec{
+			Name:        name,
+			Type:        optionTypeName(sf.Type),
+			Description: sf.Tag.Get("help"),
+			Required:    sf.Tag.Get("required") == "true",
+			Secret:      sf.Tag.Get("secret") == "true",
+		}
+		if !fv.IsZero() && !spec.Secret {
+			spec.Default = fv.Interface()
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// fieldName return option name of struct field
+func fieldName(sf reflect.StructField) string {
+	if tag, ok := sf.Tag.Lookup("option"); ok {
+		if tag = strings.Split(tag, ",")[0]; tag != "" {
+			return tag
+		}
+	}
+	return sf.Name
+}
+
+// Validate checks options against option specs declared in factory Info.
+// Required option must exist and value must be convertible to declared type.
+// Options that are not declared are not checked.
+func (f *Factory) Validate(opts Options) error {
+	for _, spec := range f.info.Options {
+		val, ok := getPath(opts, spec.Name)
+		if !ok || val == nil {
+			if spec.Required {
+				return &ValueError{Key: spec.Name, Type: spec.Type, Err: ErrMissingOption}
+			}
+			continue
+		}
+
+		t, ok := OptionType(spec.Type)
+		if !ok {
+			return fmt.Errorf("factory %s: option %s has unknown type %s", f.name, spec.Name, spec.Type)
+		}
+		if _, err := opts.convert(t, val); err != nil {
+			return withKey(spec.Name, err)
+		}
+		if err := opts.check(spec.Type, t, val); err != nil {
+			return withKey(spec.Name, err)
+		}
+	}
+	return nil
+}
+
+// check run additional check of type name, or of its elements if it is slice type name.
+// Value must be convertible to t.
+func (o Options) check(name string, t reflect.Type, val interface{}) error {
+	if strings.HasPrefix(name, "[]") {
+		if _, ok := optionChecks[name[2:]]; !ok {
+			return nil
+		}
+		items, _ := o.elements(val, t.Elem())
+		for _, item := range items {
+			if err := o.check(name[2:], t.Elem(), item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if fn, ok := optionChecks[name]; ok {
+		return fn(o, val)
+	}
+	return nil
+}
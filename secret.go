@@ -0,0 +1,166 @@
+package factory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// redacted is printed in place of secret value
+const redacted = "[REDACTED]"
+
+// Secret wraps option value that must not be printed.
+// String, %v and JSON marshalling produce [REDACTED], while option getters
+// (String, Int, Value, Decode, ...) return the real value.
+type Secret struct {
+	value interface{}
+}
+
+// NewSecret wraps value as secret
+func NewSecret(val interface{}) Secret {
+	if s, ok := val.(Secret); ok {
+		return s
+	}
+	return Secret{value: val}
+}
+
+// Value return the wrapped value
+func (s Secret) Value() interface{} {
+	return s.value
+}
+
+// String implements fmt.Stringer
+func (s Secret) String() string {
+	return redacted
+}
+
+// GoString implements fmt.GoStringer
+func (s Secret) GoString() string {
+	return redacted
+}
+
+// Format implements fmt.Formatter so that every verb is redacted
+func (s Secret) Format(f fmt.State, _ rune) {
+	io.WriteString(f, redacted)
+}
+
+// MarshalJSON implements json.Marshaler
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+// reveal return wrapped value if val is secret
+func reveal(val interface{}) (interface{}, bool) {
+	if s, ok := val.(Secret); ok {
+		return s.value, true
+	}
+	return val, false
+}
+
+// redact hide offending value of conversion error
+func redact(err error) error {
+	var ve *ValueError
+	if errors.As(err, &ve) {
+		cp := *ve
+		cp.Value = Secret{value: ve.Value}
+		return &cp
+	}
+	return err
+}
+
+// Redact return copy of options where values of options declared as secret
+// in factory Info are wrapped in Secret. Options is returned as is if there is no secret option.
+func (f *Factory) Redact(opts Options) Options {
+	res := opts
+	for _, spec := range f.info.Options {
+		if !spec.Secret {
+			continue
+		}
+		if val, ok := getPath(res, spec.Name); ok && val != nil {
+			res = replacePath(res, spec.Name, NewSecret(val))
+		}
+	}
+	return res
+}
+
+// replacePath return copy of options with value at dotted key path replaced.
+// Nested options along the path are copied, the original is not modified.
+func replacePath(o Options, path string, val interface{}) Options {
+	res := make(Options, len(o))
+	for k, v := range o {
+		res[k] = v
+	}
+	if _, ok := o[path]; ok {
+		res[path] = val
+		return res
+	}
+	parts := strings.SplitN(path, ".", 2)
+	if nested, ok := toOptions(o[parts[0]]); ok && len(parts) == 2 {
+		res[parts[0]] = replacePath(nested, parts[1], val)
+	}
+	return res
+}
+
+// Redacted return copy of config where secret options declared by its factory are wrapped in Secret.
+func (c Config) Redacted() Config {
+	if f := Get(c.Name); f != nil {
+		c.Options = f.Redact(c.Options)
+	}
+	return c
+}
+
+// plainConfig has the exported fields of Config without its methods and internal bookkeeping
+type plainConfig struct {
+	ID      string     `json:"id,omitempty"`
+	Name    string     `json:"name"`
+	Options Options    `json:"options"`
+	When    *Condition `json:"when,omitempty"`
+}
+
+// plain return exported fields of config with secret values redacted
+func (c Config) plain() plainConfig {
+	c = c.Redacted()
+	return plainConfig{ID: c.ID, Name: c.Name, Options: c.Options, When: c.When}
+}
+
+// String return config with secret values redacted
+func (c Config) String() string {
+	return fmt.Sprintf("%+v", c.plain())
+}
+
+// Format implements fmt.Formatter so that secret values are redacted for every verb
+func (c Config) Format(f fmt.State, verb rune) {
+	s := fmt.Sprintf(formatDirective(f, verb), c.plain())
+	if verb == 'v' && f.Flag('#') {
+		s = "factory.Config" + strings.TrimPrefix(s, "factory.plainConfig")
+	}
+	io.WriteString(f, s)
+}
+
+// MarshalJSON implements json.Marshaler, secret values are redacted
+func (c Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.plain())
+}
+
+// formatDirective rebuild formatting directive from fmt.State
+func formatDirective(f fmt.State, verb rune) string {
+	sb := strings.Builder{}
+	sb.WriteByte('%')
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			sb.WriteRune(flag)
+		}
+	}
+	if w, ok := f.Width(); ok {
+		sb.WriteString(strconv.Itoa(w))
+	}
+	if p, ok := f.Precision(); ok {
+		sb.WriteByte('.')
+		sb.WriteString(strconv.Itoa(p))
+	}
+	sb.WriteRune(verb)
+	return sb.String()
+}
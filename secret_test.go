@@ -0,0 +1,134 @@
+package factory_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+// vault object keeps the secret given to constructor
+type vault struct {
+	user     string
+	password string
+	tokens   []string
+}
+
+func (v *vault) ID() string {
+	return "Vault"
+}
+
+func init() {
+	info := factory.Info{
+		Name:        "vault",
+		Description: "Vault used for testing secret options",
+		Version:     "v0.1.0",
+		License:     "MIT",
+		Options: []factory.OptionSpec{
+			{Name: "user", Type: "string"},
+			{Name: "password", Type: "string", Secret: true},
+			{Name: "db.port", Type: "port", Secret: true},
+			{Name: "tokens", Type: "[]string", Secret: true},
+		},
+	}
+	factory.RegisterTyped("vault", info, func(args factory.Options) (*vault, error) {
+		if _, err := factory.Value[uint16](args, "db.port"); err != nil {
+			return nil, err
+		}
+		return &vault{
+			user:     args.String("user"),
+			password: args.String("password"),
+			tokens:   args.StringSlice("tokens"),
+		}, nil
+	})
+}
+
+func TestSecretOptions(t *testing.T) {
+	c := factory.Config{
+		Name: "vault",
+		Options: factory.Options{
+			"user":     "admin",
+			"password": "hunter2",
+			"db":       factory.Options{"port": 5432},
+		},
+	}
+
+	// constructor receives the real value
+	v, err := factory.CreateAs[*vault](c)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", v.user)
+	assert.Equal(t, "hunter2", v.password)
+
+	for _, s := range []string{c.String(), fmt.Sprint(c), fmt.Sprintf("%+v", c), fmt.Sprintf("%#v", c)} {
+		assert.NotContains(t, s, "hunter2")
+		assert.NotContains(t, s, "5432")
+		assert.Contains(t, s, "[REDACTED]")
+		assert.Contains(t, s, "admin")
+	}
+
+	data, err := json.Marshal(c)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.Contains(t, string(data), `"password":"[REDACTED]"`)
+
+	// original options are not modified
+	assert.Equal(t, "hunter2", c.Options["password"])
+	assert.Equal(t, 5432, c.Options["db"].(factory.Options)["port"])
+
+	// validation and construction errors do not contain the secret
+	c.Options = factory.Options{"password": "hunter2", "db": factory.Options{"port": "hunter2"}}
+	_, err = factory.Create(c)
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "hunter2")
+	assert.Contains(t, err.Error(), "db.port")
+
+	opts := factory.Options{"password": factory.NewSecret("hunter2")}
+	assert.NotContains(t, fmt.Sprint(opts), "hunter2")
+	assert.Equal(t, "hunter2", opts.String("password"))
+}
+
+func TestSecretSliceKeepsSource(t *testing.T) {
+	tokens := []interface{}{"t1", "t2"}
+	c := factory.Config{
+		Name:    "vault",
+		Options: factory.Options{"tokens": tokens},
+	}
+
+	v, err := factory.CreateAs[*vault](c)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"t1", "t2"}, v.tokens)
+	assert.Equal(t, []interface{}{"t1", "t2"}, tokens, "Source slice shall not be wrapped in Secret")
+	assert.Equal(t, `[]interface {}{"t1", "t2"}`, fmt.Sprintf("%#v", c.Options["tokens"]))
+
+	op := factory.Options{"tokens": factory.NewSecret(tokens)}
+	assert.Equal(t, []string{"t1", "t2"}, op.StringSlice("tokens"))
+	ts, err := factory.Slice[string](op, "tokens")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"t1", "t2"}, ts)
+	assert.Equal(t, []interface{}{"t1", "t2"}, tokens)
+}
+
+func TestConfigFormatExportedFields(t *testing.T) {
+	c := factory.Config{ID: "v1", Name: "vault", Options: factory.Options{"user": "admin"}}
+	assert.Equal(t, "{ID:v1 Name:vault Options:map[user:admin] When:<nil>}", fmt.Sprintf("%+v", c))
+	assert.Equal(t, `factory.Config{ID:"v1", Name:"vault", Options:factory.Options{"user":"admin"}, When:(*factory.Condition)(nil)}`,
+		fmt.Sprintf("%#v", c))
+	assert.Equal(t, "{v1 vault map[user:admin] <nil>}", fmt.Sprint(c))
+}
+
+func TestDescribeRedactsSecretDefaults(t *testing.T) {
+	f := factory.Get("vault")
+	d := f.Describe()
+	assert.Equal(t, "vault", d.Name)
+	assert.Equal(t, f.Info().Options[1].Name, d.Options[1].Name)
+
+	factory.Register("vault-default", factory.Info{Options: []factory.OptionSpec{
+		{Name: "password", Default: "changeme", Secret: true},
+	}}, nil)
+	defer factory.Unregister("vault-default")
+	data, err := json.Marshal(factory.Get("vault-default").Describe())
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"vault-default","options":[{"name":"password","type":"","default":"[REDACTED]","secret":true}]}`, string(data))
+}
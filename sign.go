@@ -0,0 +1,313 @@
+package factory
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// SignaturePrefix marks detached signature: sig:v1:<key id>:<base64 ed25519 signature>
+const SignaturePrefix = "sig:v1:"
+
+// SignatureExt is appended to configuration file name to locate its detached signature
+const SignatureExt = ".sig"
+
+var (
+	// ErrUnsigned reported when configuration has no signature and unsigned configuration is denied
+	ErrUnsigned = errors.New("configuration is not signed")
+
+	// ErrBadSignature reported when signature does not match configuration or its key is not trusted
+	ErrBadSignature = errors.New("invalid configuration signature")
+)
+
+// UnsignedPolicy decides what happens when configuration without signature is loaded
+type UnsignedPolicy int
+
+// Unsigned configuration policies
+const (
+	AllowUnsigned UnsignedPolicy = iota
+	WarnUnsigned
+	DenyUnsigned
+)
+
+// String return policy name
+func (p UnsignedPolicy) String() string {
+	switch p {
+	case AllowUnsigned:
+		return "allow"
+	case WarnUnsigned:
+		return "warn"
+	case DenyUnsigned:
+		return "deny"
+	}
+	return fmt.Sprintf("UnsignedPolicy(%d)", int(p))
+}
+
+var (
+	trustMu     sync.RWMutex
+	trustStore  *TrustStore
+	trustPolicy UnsignedPolicy
+)
+
+// SetTrustStore enables signature verification in LoadConfig and LoadManifest.
+// Signature is read from file with SignatureExt appended to the configuration file name,
+// policy decides what happens when the signature file does not exist.
+// Verification is disabled if ts is nil.
+func SetTrustStore(ts *TrustStore, policy UnsignedPolicy) {
+	trustMu.Lock()
+	defer trustMu.Unlock()
+	trustStore = ts
+	trustPolicy = policy
+}
+
+// trustSettings return configured trust store and policy
+func trustSettings() (*TrustStore, UnsignedPolicy) {
+	trustMu.RLock()
+	defer trustMu.RUnlock()
+	return trustStore, trustPolicy
+}
+
+// TrustStore holds ed25519 public keys, identified by id, trusted to sign configuration.
+type TrustStore struct {
+	mu   sync.RWMutex
+	keys map[string]ed25519.PublicKey
+}
+
+// NewTrustStore creates empty trust store
+func NewTrustStore() *TrustStore {
+	return &TrustStore{
+		keys: map[string]ed25519.PublicKey{},
+	}
+}
+
+// Add adds trusted public key with given id
+func (ts *TrustStore) Add(id string, key ed25519.PublicKey) error {
+	if id == "" || strings.ContainsAny(id, ": \t\r\n") {
+		return fmt.Errorf("invalid key id %q", id)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("key %s: invalid public key size %d", id, len(key))
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if _, ok := ts.keys[id]; ok {
+		return fmt.Errorf("key %s already exists", id)
+	}
+	ts.keys[id] = append(ed25519.PublicKey{}, key...)
+	return nil
+}
+
+// ParseTrustStore parses trust file content.
+// Each line contains key id and base64 encoded public key separated by whitespace,
+// empty lines and lines starting with # are ignored.
+func ParseTrustStore(data []byte) (*TrustStore, error) {
+	ts := NewTrustStore()
+	err := parseKeyLines(data, func(id string, key []byte) error {
+		return ts.Add(id, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// LoadTrustFile reads trust store from file (see ParseTrustStore)
+func LoadTrustFile(path string) (*TrustStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := ParseTrustStore(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ts, nil
+}
+
+// Verify checks detached signature of data
+func (ts *TrustStore) Verify(data []byte, sig string) error {
+	sig = strings.TrimSpace(sig)
+	body := strings.TrimPrefix(sig, SignaturePrefix)
+	parts := strings.SplitN(body, ":", 2)
+	if body == sig || len(parts) != 2 {
+		return fmt.Errorf("%w: malformed signature", ErrBadSignature)
+	}
+	ts.mu.RLock()
+	key, ok := ts.keys[parts[0]]
+	ts.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: key %s is not trusted", ErrBadSignature, parts[0])
+	}
+	raw, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrBadSignature)
+	}
+	if !ed25519.Verify(key, data, raw) {
+		return fmt.Errorf("%w: content does not match signature of key %s", ErrBadSignature, parts[0])
+	}
+	return nil
+}
+
+// VerifyConfig checks detached signature of config
+func (ts *TrustStore) VerifyConfig(c Config, sig string) error {
+	data, err := c.Canonical()
+	if err != nil {
+		return err
+	}
+	return ts.Verify(data, sig)
+}
+
+// VerifyManifest checks detached signature of manifest
+func (ts *TrustStore) VerifyManifest(m Manifest, sig string) error {
+	data, err := m.canonical()
+	if err != nil {
+		return err
+	}
+	return ts.Verify(data, sig)
+}
+
+// SigningKey is ed25519 private key used to sign configuration
+type SigningKey struct {
+	ID  string
+	Key ed25519.PrivateKey
+}
+
+// GenerateSigningKey creates new signing key with given id
+func GenerateSigningKey(id string) (*SigningKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKey{ID: id, Key: priv}, nil
+}
+
+// ParseSigningKey parses key file content containing single line of
+// key id and base64 encoded ed25519 seed separated by whitespace.
+func ParseSigningKey(data []byte) (*SigningKey, error) {
+	var sk *SigningKey
+	err := parseKeyLines(data, func(id string, seed []byte) error {
+		if sk != nil {
+			return errors.New("expecting single signing key")
+		}
+		if len(seed) != ed25519.SeedSize {
+			return fmt.Errorf("key %s: invalid seed size %d", id, len(seed))
+		}
+		sk = &SigningKey{ID: id, Key: ed25519.NewKeyFromSeed(seed)}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sk == nil {
+		return nil, errors.New("no signing key")
+	}
+	return sk, nil
+}
+
+// LoadSigningKey reads signing key from file (see ParseSigningKey)
+func LoadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := ParseSigningKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return sk, nil
+}
+
+// Marshal return key file content of signing key
+func (sk *SigningKey) Marshal() []byte {
+	return []byte(sk.ID + " " + base64.StdEncoding.EncodeToString(sk.Key.Seed()) + "\n")
+}
+
+// Public return trust file line of the public key
+func (sk *SigningKey) Public() string {
+	pub := sk.Key.Public().(ed25519.PublicKey)
+	return sk.ID + " " + base64.StdEncoding.EncodeToString(pub)
+}
+
+// Sign return detached signature of data
+func (sk *SigningKey) Sign(data []byte) string {
+	return SignaturePrefix + sk.ID + ":" + base64.StdEncoding.EncodeToString(ed25519.Sign(sk.Key, data))
+}
+
+// SignConfig return detached signature of config
+func (sk *SigningKey) SignConfig(c Config) (string, error) {
+	data, err := c.Canonical()
+	if err != nil {
+		return "", err
+	}
+	return sk.Sign(data), nil
+}
+
+// SignManifest return detached signature of manifest
+func (sk *SigningKey) SignManifest(m Manifest) (string, error) {
+	data, err := m.canonical()
+	if err != nil {
+		return "", err
+	}
+	return sk.Sign(data), nil
+}
+
+// verifyFile verifies signature of configuration loaded from path using configured trust store
+func verifyFile(path string, canonical func() ([]byte, error)) error {
+	ts, policy := trustSettings()
+	if ts == nil {
+		return nil
+	}
+	sig, err := os.ReadFile(path + SignatureExt)
+	if errors.Is(err, os.ErrNotExist) {
+		switch policy {
+		case DenyUnsigned:
+			return fmt.Errorf("%s: %w", path, ErrUnsigned)
+		case WarnUnsigned:
+			log.Printf("factory: warning: %s: %v", path, ErrUnsigned)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := canonical()
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := ts.Verify(data, string(sig)); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// parseKeyLines parses lines of key id and base64 encoded key
+func parseKeyLines(data []byte, add func(id string, key []byte) error) error {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return fmt.Errorf("line %d: expecting <id> <base64 key>", n)
+		}
+		key, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return fmt.Errorf("line %d: key %s is not valid base64", n, fields[0])
+		}
+		if err := add(fields[0], key); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return sc.Err()
+}
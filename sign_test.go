@@ -0,0 +1,97 @@
+package factory_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignedManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.json": `{
+			"include": ["base.json"],
+			"instances": [{"id": "log", "options": {"level": "debug"}}]
+		}`,
+		"base.json": `{
+			"instances": [{"id": "log", "name": "file", "options": {"filename": "app.log"}}],
+			"profiles": {"prod": {"log": {"level": "error"}}}
+		}`,
+		"unsigned.json": `{"instances": [{"name": "file"}]}`,
+	})
+	path := filepath.Join(dir, "main.json")
+
+	sk, err := factory.GenerateSigningKey("ci")
+	assert.NoError(t, err)
+	m, err := factory.LoadManifest(path)
+	assert.NoError(t, err)
+	sig, err := sk.SignManifest(*m)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path+factory.SignatureExt, []byte(sig+"\n"), 0644))
+
+	ts, err := factory.ParseTrustStore([]byte("# trusted keys\n" + sk.Public() + "\n"))
+	assert.NoError(t, err)
+	factory.SetTrustStore(ts, factory.DenyUnsigned)
+	t.Cleanup(func() {
+		factory.SetTrustStore(nil, factory.AllowUnsigned)
+	})
+
+	_, err = factory.LoadManifest(path)
+	assert.NoError(t, err)
+
+	_, err = factory.LoadManifest(filepath.Join(dir, "unsigned.json"))
+	assert.True(t, errors.Is(err, factory.ErrUnsigned))
+	factory.SetTrustStore(ts, factory.WarnUnsigned)
+	_, err = factory.LoadManifest(filepath.Join(dir, "unsigned.json"))
+	assert.NoError(t, err)
+
+	// tampered included file invalidates signature
+	writeFiles(t, dir, map[string]string{
+		"base.json": `{"instances": [{"id": "log", "name": "file", "options": {"filename": "/etc/passwd"}}]}`,
+	})
+	_, err = factory.LoadManifest(path)
+	assert.True(t, errors.Is(err, factory.ErrBadSignature))
+	assert.Contains(t, err.Error(), "main.json")
+
+	// key not in trust store
+	other, err := factory.GenerateSigningKey("other")
+	assert.NoError(t, err)
+	c := factory.Config{Name: "file", Options: factory.Options{"filename": "app.log"}}
+	sig, err = other.SignConfig(c)
+	assert.NoError(t, err)
+	err = ts.VerifyConfig(c, sig)
+	assert.True(t, errors.Is(err, factory.ErrBadSignature))
+	assert.Contains(t, err.Error(), "not trusted")
+}
+
+func TestSignConfig(t *testing.T) {
+	sk, err := factory.GenerateSigningKey("ci")
+	assert.NoError(t, err)
+	parsed, err := factory.ParseSigningKey(sk.Marshal())
+	assert.NoError(t, err)
+	ts := factory.NewTrustStore()
+	pub, err := factory.ParseTrustStore([]byte(sk.Public()))
+	assert.NoError(t, err)
+	assert.NotNil(t, pub)
+
+	c := factory.Config{
+		Name:    "vault",
+		Options: factory.Options{"user": "admin", "password": "hunter2"},
+	}
+	sig, err := parsed.SignConfig(c)
+	assert.NoError(t, err)
+	assert.NoError(t, pub.VerifyConfig(c, sig))
+	assert.Error(t, ts.VerifyConfig(c, sig))
+
+	// secret wrapper does not change the signed content
+	c.Options = factory.Options{"user": "admin", "password": factory.NewSecret("hunter2")}
+	assert.NoError(t, pub.VerifyConfig(c, sig))
+
+	c.Options = factory.Options{"user": "root", "password": "hunter2"}
+	assert.True(t, errors.Is(pub.VerifyConfig(c, sig), factory.ErrBadSignature))
+	assert.Error(t, pub.VerifyConfig(c, "garbage"))
+}
@@ -0,0 +1,131 @@
+package factory
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	separatorMu sync.RWMutex
+	separator   = ","
+)
+
+// SetSliceSeparator sets separator used to split string value into slice items.
+// Default separator is comma. Empty separator disables splitting.
+func SetSliceSeparator(sep string) {
+	separatorMu.Lock()
+	defer separatorMu.Unlock()
+	separator = sep
+}
+
+// sliceSeparator return current separator
+func sliceSeparator() string {
+	separatorMu.RLock()
+	defer separatorMu.RUnlock()
+	return separator
+}
+
+// elements return items of slice or array value with element type elem.
+// String is treated as JSON array if it is enclosed in brackets,
+// otherwise it is split using configured separator unless text of elem may contain
+// the separator (see splittable).
+func (o Options) elements(val interface{}, elem reflect.Type) ([]interface{}, bool) {
+	if raw, ok := reveal(val); ok {
+		// items of secret are secret too
+		items, ok := o.elements(raw, elem)
+		// items may share the caller's slice, do not wrap them in place
+		items = append([]interface{}(nil), items...)
+		for i := range items {
+			items[i] = NewSecret(items[i])
+		}
+		return items, ok
+	}
+
+	switch v := val.(type) {
+	case []interface{}:
+		return v, true
+	case string:
+		return o.splitString(v, splittable(elem))
+	}
+
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	n := rv.Len()
+	items := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		ev := rv.Index(i)
+		if !ev.CanInterface() {
+			return nil, false
+		}
+		items = append(items, ev.Interface())
+	}
+	return items, true
+}
+
+// unsplittable are types whose text may legitimately contain the separator
+var unsplittable = map[reflect.Type]bool{
+	reflect.TypeOf(time.Time{}):         true,
+	reflect.TypeOf(&regexp.Regexp{}):    true,
+	reflect.TypeOf(map[string]string{}): true,
+}
+
+// splittable return false if text of type t may contain the separator, e.g. time, map or regexp
+func splittable(t reflect.Type) bool {
+	return t.Kind() != reflect.Map && !unsplittable[t]
+}
+
+// splitString split JSON array string into items.
+// Other string is split using configured separator if split is true, otherwise it is a single item.
+func (o Options) splitString(s string, split bool) ([]interface{}, bool) {
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return []interface{}{}, true
+	}
+	if strings.HasPrefix(str, "[") && strings.HasSuffix(str, "]") {
+		dec := json.NewDecoder(strings.NewReader(str))
+		dec.UseNumber()
+		var items []interface{}
+		if err := dec.Decode(&items); err == nil {
+			return items, true
+		}
+	}
+
+	parts := []string{str}
+	if sep := sliceSeparator(); sep != "" && split {
+		parts = strings.Split(str, sep)
+	}
+	items := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		items = append(items, strings.TrimSpace(part))
+	}
+	return items, true
+}
+
+// convertItems convert each element of option value using conv.
+// Default items are returned if key does not exist or the value is not a slice.
+func convertItems[T any](o Options, key string, def []T, conv func(val interface{}) T) []T {
+	val, ok := o[key]
+	if !ok || val == nil {
+		return def
+	}
+	if v, ok := val.([]T); ok {
+		return v
+	}
+
+	items, ok := o.elements(val, typeOf[T]())
+	if !ok {
+		return def
+	}
+	res := make([]T, 0, len(items))
+	for _, item := range items {
+		res = append(res, conv(item))
+	}
+	return res
+}
@@ -0,0 +1,87 @@
+package factory_test
+
+import (
+	"io/fs"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSliceGetters(t *testing.T) {
+	op := factory.Options{
+		"csv":    "a, b ,c",
+		"json":   `[1, 2.5, "3"]`,
+		"nums":   []string{"1", "2", "3"},
+		"arr":    [3]int{4, 5, 6},
+		"empty":  []int{},
+		"flags":  []int{1, 0, 2},
+		"ds":     "1s,2m,1d",
+		"ts":     []interface{}{"2022-01-02T08:14:00Z", 1641111240},
+		"us":     "[10, 20]",
+		"scalar": 10,
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, op.StringSlice("csv"))
+	assert.Equal(t, []string{"1", "2.5", "3"}, op.StringSlice("json"))
+	assert.Equal(t, []string{}, op.StringSlice("empty"), "Empty slice is not nil")
+	assert.Equal(t, []string{"4", "5", "6"}, op.StringSlice("arr"))
+	assert.Equal(t, []int64{1, 2, 3}, op.IntSlice("nums"))
+	assert.Equal(t, []float64{1, 2.5, 3}, op.FloatSlice("json"))
+	assert.Equal(t, []uint64{10, 20}, op.UintSlice("us"))
+	assert.Equal(t, []bool{true, false, true}, op.BoolSlice("flags"))
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Minute, 24 * time.Hour}, op.DurationSlice("ds"))
+
+	ts := op.TimeSlice("ts")
+	assert.Len(t, ts, 2)
+	assert.True(t, ts[0].Equal(ts[1]))
+
+	// non slice value return default
+	assert.Equal(t, []int64{7}, op.IntSlice("scalar", 7))
+	assert.Equal(t, []int64{7}, op.IntSlice("missing", 7))
+
+	factory.SetSliceSeparator(";")
+	defer factory.SetSliceSeparator(",")
+	assert.Equal(t, []string{"a", "b"}, factory.Options{"s": "a;b"}.StringSlice("s"))
+
+	is, err := factory.Slice[int](factory.Options{"s": "1;x"}, "s")
+	assert.Nil(t, is)
+	assert.Equal(t, `options.s[1]: invalid int "x": invalid syntax`, err.Error())
+}
+
+func TestSliceSplitByElementType(t *testing.T) {
+	op := factory.Options{
+		"time":  "Mon, 02 Jan 2006 15:04:05 MST",
+		"times": `["2022-01-02T08:14:00Z", "2022-01-03T08:14:00Z"]`,
+		"map":   "a=1,b=2",
+		"sizes": "1KiB, 2KiB",
+		"ips":   "10.0.0.1,10.0.0.2",
+		"addrs": "a:1, b:2",
+		"modes": "0644,0755",
+		"re":    "^a{1,3}$",
+	}
+
+	ts, err := factory.Slice[time.Time](op, "time")
+	assert.NoError(t, err)
+	if assert.Len(t, ts, 1, "Time text shall not be split") {
+		assert.Equal(t, 2006, ts[0].Year())
+	}
+	if ts := op.TimeSlice("time"); assert.Len(t, ts, 1) {
+		assert.False(t, ts[0].IsZero())
+	}
+	assert.Len(t, op.TimeSlice("times"), 2)
+
+	assert.Equal(t, []map[string]string{{"a": "1", "b": "2"}}, op.StringMapSlice("map"))
+	assert.Equal(t, []factory.ByteSize{factory.KiB, 2 * factory.KiB}, op.SizeSlice("sizes"))
+
+	if ips := op.IPSlice("ips"); assert.Len(t, ips, 2) {
+		assert.True(t, ips[1].Equal(net.IPv4(10, 0, 0, 2)))
+	}
+	assert.Equal(t, []factory.HostPort{{Host: "a", Port: 1}, {Host: "b", Port: 2}}, op.HostPortSlice("addrs"))
+	assert.Equal(t, []fs.FileMode{0644, 0755}, op.FileModeSlice("modes"))
+	if res := op.RegexpSlice("re"); assert.Len(t, res, 1, "Regexp shall not be split") {
+		assert.True(t, res[0].MatchString("aa"))
+	}
+}
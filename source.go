@@ -0,0 +1,211 @@
+package factory
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Position of a value in configuration file
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// IsValid return true if position is known
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String return position in file:line:column format
+func (p Position) String() string {
+	if !p.IsValid() {
+		return p.File
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// SourceError is an error annotated with position in configuration file
+type SourceError struct {
+	Pos Position
+	Err error
+}
+
+// Error implements error interface
+func (e *SourceError) Error() string {
+	return e.Pos.String() + ": " + e.Err.Error()
+}
+
+// Unwrap return underlying error
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// source holds position of config and its option values.
+// It is never modified after creation since configs are copied by value.
+type source struct {
+	pos     Position
+	options map[string]Position // keyed by option key path, e.g. timeout, db.host, hosts[1]
+}
+
+// overlay return new source where positions of other take precedence
+func (s *source) overlay(other *source) *source {
+	if s == nil {
+		return other
+	}
+	if other == nil {
+		return s
+	}
+	res := &source{
+		pos:     s.pos,
+		options: make(map[string]Position, len(s.options)+len(other.options)),
+	}
+	if !res.pos.IsValid() {
+		res.pos = other.pos
+	}
+	for key, pos := range s.options {
+		res.options[key] = pos
+	}
+	for key, pos := range other.options {
+		res.options[key] = pos
+	}
+	return res
+}
+
+// Pos return position of the config in file it was loaded from
+func (c Config) Pos() Position {
+	if c.src == nil {
+		return Position{}
+	}
+	return c.src.pos
+}
+
+// OptionPos return position of option value with given key path.
+// If the option position is not known, position of its parent or the config is returned.
+func (c Config) OptionPos(key string) Position {
+	if c.src == nil {
+		return Position{}
+	}
+	for key != "" {
+		if pos, ok := c.src.options[key]; ok {
+			return pos
+		}
+		i := strings.LastIndexAny(key, ".[")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return c.src.pos
+}
+
+// annotate add position of offending option (or config) to the error
+func (c Config) annotate(err error) error {
+	if err == nil || c.src == nil {
+		return err
+	}
+	var se *SourceError
+	if errors.As(err, &se) {
+		return err
+	}
+
+	pos := c.src.pos
+	var ve *ValueError
+	if errors.As(err, &ve) && ve.Key != "" {
+		pos = c.OptionPos(ve.Key)
+	}
+	if !pos.IsValid() {
+		return err
+	}
+	return &SourceError{Pos: pos, Err: err}
+}
+
+// valuePositions return position of every value in JSON document keyed by path,
+// e.g. instances[0].options.timeout
+func valuePositions(file string, data []byte) map[string]Position {
+	res := map[string]Position{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	w := jsonWalker{file: file, data: data, dec: dec, res: res}
+	// document is already validated by json.Unmarshal
+	_ = w.walk("")
+	return res
+}
+
+// jsonWalker records value position while reading JSON tokens
+type jsonWalker struct {
+	file string
+	data []byte
+	dec  *json.Decoder
+	res  map[string]Position
+}
+
+// walk read a value and record its position
+func (w *jsonWalker) walk(path string) error {
+	off := w.dec.InputOffset()
+	for off < int64(len(w.data)) && strings.IndexByte(" \t\r\n:,", w.data[off]) >= 0 {
+		off++
+	}
+	line, col := position(w.data, off)
+	w.res[path] = Position{File: w.file, Line: line, Column: col}
+
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		for w.dec.More() {
+			kt, err := w.dec.Token()
+			if err != nil {
+				return err
+			}
+			if err := w.walk(joinKey(path, kt.(string))); err != nil {
+				return err
+			}
+		}
+		_, err = w.dec.Token()
+	case json.Delim('['):
+		for i := 0; w.dec.More(); i++ {
+			if err := w.walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		_, err = w.dec.Token()
+	}
+	return err
+}
+
+// sourceAt build source of config located at path from value positions
+func sourceAt(positions map[string]Position, path string) *source {
+	return &source{
+		pos:     positions[path],
+		options: subPositions(positions, joinKey(path, "options")),
+	}
+}
+
+// overridesAt build source of profile overrides located at path
+func overridesAt(positions map[string]Position, path string) *source {
+	return &source{
+		options: subPositions(positions, path),
+	}
+}
+
+// subPositions return positions of values nested below path, keyed by relative path
+func subPositions(positions map[string]Position, path string) map[string]Position {
+	res := map[string]Position{}
+	for key, pos := range positions {
+		rest := strings.TrimPrefix(key, path)
+		switch {
+		case rest == key || rest == "":
+			continue
+		case rest[0] == '.':
+			res[rest[1:]] = pos
+		case rest[0] == '[':
+			res[rest] = pos
+		}
+	}
+	return res
+}
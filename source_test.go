@@ -0,0 +1,95 @@
+package factory_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ipsusila/factory"
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/ipsusila/factory/impl/file"
+)
+
+// timer object used by tests requiring option specs
+type timer struct {
+	timeout time.Duration
+}
+
+func (t *timer) ID() string {
+	return "Timer"
+}
+
+func init() {
+	info := factory.Info{
+		Name:        "timer",
+		Description: "Timer used for testing option validation",
+		Version:     "v0.1.0",
+		License:     "MIT",
+		Options: []factory.OptionSpec{
+			{Name: "timeout", Type: "duration", Default: "1s", Description: "Timer timeout"},
+			{Name: "retry", Type: "int", Description: "Number of retries"},
+		},
+	}
+	factory.RegisterTyped("timer", info, func(args factory.Options) (*timer, error) {
+		d, err := factory.Value(args, "timeout", time.Second)
+		if err != nil {
+			return nil, err
+		}
+		return &timer{timeout: d}, nil
+	})
+}
+
+func TestSourcePosition(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"services.json": `{
+  "instances": [
+    {"id": "ok", "name": "timer", "options": {"timeout": "5s"}},
+    {
+      "id": "slow",
+      "name": "timer",
+      "options": {
+        "timeout": "15mins"
+      }
+    },
+    {"id": "nofile", "name": "file"}
+  ],
+  "profiles": {
+    "prod": {
+      "ok": {"retry": "many"}
+    }
+  }
+}`,
+		"config.json": "{\n  \"name\": \"timer\",\n  \"options\": {\"retry\": 1.5}\n}",
+	})
+
+	path := filepath.Join(dir, "services.json")
+	m, err := factory.LoadManifest(path)
+	assert.Nil(t, err)
+
+	configs, err := m.Resolve()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, configs[0].Pos().Line)
+	assert.Equal(t, 5, configs[0].Pos().Column)
+
+	_, err = factory.Create(configs[1])
+	assert.Equal(t, path+`:8:20: options.timeout: invalid duration "15mins"`, err.Error())
+	var ve *factory.ValueError
+	assert.True(t, errors.As(err, &ve))
+
+	err = factory.Validate(configs[2])
+	assert.Equal(t, path+":11:5: options.filename: required option is missing", err.Error())
+	assert.True(t, errors.Is(err, factory.ErrMissingOption))
+
+	// error in profile override points to the profile
+	err = m.Validate("prod")
+	assert.Equal(t, path+`:15:23: options.retry: invalid int "many": invalid syntax`, err.Error())
+
+	cpath := filepath.Join(dir, "config.json")
+	c, err := factory.LoadConfig(cpath)
+	assert.Nil(t, err)
+	_, err = factory.Create(c)
+	assert.Equal(t, cpath+":3:24: options.retry: invalid int 1.5", err.Error())
+}
@@ -0,0 +1,75 @@
+package factory
+
+import (
+	"context"
+	"sync"
+)
+
+// Attribute is key value pair attached to span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attributes set on Create spans
+const (
+	AttrFactory  = "factory.name"
+	AttrVersion  = "factory.version"
+	AttrInstance = "factory.instance"
+	AttrObjectID = "factory.object_id"
+)
+
+// Tracer starts spans around object construction.
+// Span started with context returned by an earlier Start must be child of that span,
+// so objects created by constructor using CreateContext are nested under their parent.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a traced operation
+type Span interface {
+	// SetAttributes adds attributes to span
+	SetAttributes(attrs ...Attribute)
+
+	// End finishes span, err is the error of the operation if it failed
+	End(err error)
+}
+
+// noopTracer is the default tracer, it does nothing
+type noopTracer struct{}
+
+// noopSpan is span of noopTracer
+type noopSpan struct{}
+
+// Start implements Tracer
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+// SetAttributes implements Span
+func (noopSpan) SetAttributes(...Attribute) {}
+
+// End implements Span
+func (noopSpan) End(error) {}
+
+var (
+	tracerMu sync.RWMutex
+	tracer   Tracer = noopTracer{}
+)
+
+// SetTracer sets tracer invoked around every Create. Nil tracer disables tracing.
+func SetTracer(t Tracer) {
+	if t == nil {
+		t = noopTracer{}
+	}
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+	tracer = t
+}
+
+// currentTracer return tracer set by SetTracer
+func currentTracer() Tracer {
+	tracerMu.RLock()
+	defer tracerMu.RUnlock()
+	return tracer
+}